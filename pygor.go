@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math/big"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/go-python/gpython/ast"
@@ -21,6 +23,8 @@ var (
 	verbose      bool
 	lineno       bool
 	mainpackage  bool
+	ignoreErrors bool
+	bigInts      bool
 
 	gokeywords = map[string]string{
 		// Convert python names to pygor names
@@ -67,9 +71,15 @@ var (
 	goRuntime = "github.com/raff/pygor/runtime"
 
 	goAny             = jen.Qual(goRuntime, "Any")
+	goInt             = jen.Qual(goRuntime, "Int")
 	goList            = jen.Qual(goRuntime, "List")
 	goTuple           = jen.Qual(goRuntime, "Tuple")
 	goDict            = jen.Qual(goRuntime, "Dict")
+	goOrderedDict     = jen.Op("*").Qual(goRuntime, "OrderedDict")
+	goSet             = jen.Qual(goRuntime, "Set")
+	goSetOf           = jen.Qual(goRuntime, "SetOf")
+	goGenerator       = jen.Qual(goRuntime, "Generator")
+	goRange           = jen.Qual(goRuntime, "Range")
 	goAssert          = jen.Qual(goRuntime, "Assert")
 	goContains        = jen.Qual(goRuntime, "Contains")
 	goException       = jen.Qual(goRuntime, "PyException")
@@ -102,6 +112,23 @@ func unknown(typ string, v interface{}) *jen.Statement {
 	return jen.Lit(msg)
 }
 
+// report an error in the python source, at the position of node
+// (and stop, unless we are ignoring errors)
+func errorf(node ast.Ast, format string, args ...interface{}) {
+	msg := fmt.Sprintf("at line %d, col %d: ", node.GetLineno(), node.GetColOffset()) +
+		fmt.Sprintf(format, args...)
+
+	if panicUnknown {
+		panic(msg)
+	}
+
+	if ignoreErrors {
+		log.Println("ERROR", msg)
+	} else {
+		log.Fatal(msg)
+	}
+}
+
 func trimlines(s py.String) string {
 	var lines []string
 
@@ -138,10 +165,34 @@ func (r ScopeReturn) String() string {
 	return "UNKNOWN"
 }
 
+type controlKind int
+
+const (
+	controlLoop   controlKind = iota // a loop body
+	controlTry                       // a try body, translated to a closure
+	controlExcept                    // an except clause, translated to a switch case
+)
+
+// how break and continue are translated in a block
+type loopControl struct {
+	kind controlKind
+
+	broken string // for a loop with an else clause, the flag set by break
+	label  string // for a loop, the label used by break in an except clause
+
+	breakFlag, continueFlag string // for a try body, the flags set by break and continue
+}
+
 type Scope struct {
 	level   int // nesting level
-	vars    map[string]struct{}
+	vars    map[string]*Type
 	imports map[string]string
+	funcs   map[string]*Signature
+	classes map[string]bool
+	class   string     // the class of the method being parsed
+	names   *funcNames // for a function scope, the names bound in the function
+	sig     *Signature // for a function scope, the signature of the function
+	globals []string   // for the module scope, the global names that are not defined in the module
 	main    bool
 
 	file *jen.File
@@ -152,18 +203,26 @@ type Scope struct {
 
 	returnType ScopeReturn
 
+	control *loopControl        // for the bodies of loops, try statements and except clauses
+	pre     *jen.Statement      // the statements added before the statement being translated (see withHoisting)
+	hoist   map[ast.Expr]bool   // the expressions of the statement being translated that can be lowered before it
+	temps   map[ast.Expr]string // expressions evaluated before the statement, in a temporary variable
+	counter int                 // for the module scope, the last number used for generated names
+
 	next *Scope
 	prev *Scope
 }
 
 func NewScope(f *jen.File, imp ...map[string]string) *Scope {
-	scope := &Scope{vars: make(map[string]struct{}), parsed: jen.Null(), file: f}
+	scope := &Scope{vars: make(map[string]*Type), parsed: jen.Null(), file: f}
 	if len(imp) > 0 {
 		scope.imports = imp[0]
 	} else {
 		scope.imports = make(map[string]string)
 	}
 
+	scope.funcs = make(map[string]*Signature)
+	scope.classes = make(map[string]bool)
 	return scope
 }
 
@@ -189,6 +248,9 @@ func (s *Scope) Top() bool {
 
 func (s *Scope) Push() *Scope {
 	s.next = NewScope(s.file, s.imports)
+	s.next.funcs = s.funcs
+	s.next.classes = s.classes
+	s.next.class = s.class
 	s.next.prev = s
 	s.next.level = s.level + 1
 	if verbose {
@@ -226,965 +288,6279 @@ func (s *Scope) Add(stmt *jen.Statement) {
 	s.body = append(s.body, stmt)
 }
 
-// check if the element in the expression list are new names
-// (and add them to the list of known names)
-func (s *Scope) newNames(lexpr []ast.Expr) (ret bool) {
-	for _, x := range lexpr {
-		var nn string
+// the scope of the function being parsed (nil at module level)
+func (s *Scope) function() *Scope {
+	for curr := s; curr != nil; curr = curr.prev {
+		if curr.names != nil {
+			return curr
+		}
+	}
 
-		switch t := x.(type) {
-		case *ast.Name:
-			nn = string(t.Id)
+	return nil
+}
 
-		default:
-			continue
+// the signature of the function (or Class.method) name visible from this scope:
+// nested functions are registered in the scope of the function defining them,
+// and a variable (or a local name not defined yet) hides the functions of the outer scopes
+func (s *Scope) lookupFunc(name string) *Signature {
+	for curr := s; curr != nil; curr = curr.prev {
+		if _, ok := curr.vars[name]; ok {
+			return nil
 		}
 
-		// if we have seen the name before, in any scope,
-		// it's defined. Otherwise "define" it in the current scope.
-		// (but if forceNew is set, these are new names in the scope)
-		found := false
-
-		for curr := s; curr != nil; curr = curr.prev {
-			if _, ok := curr.vars[nn]; ok {
-				found = true
-				break
-			}
+		if sig := curr.funcs[name]; sig != nil {
+			return sig
 		}
 
-		if !found {
-			s.vars[nn] = struct{}{}
-			ret = true
+		if curr.names != nil && curr.names.locals[name] {
+			return nil
 		}
 	}
 
-	return
+	return nil
 }
 
-func (s *Scope) addName(id ast.Identifier) {
-	s.vars[string(id)] = struct{}{}
+// a new name for a generated variable or label (as _broken1)
+func (s *Scope) generatedName(prefix string) string {
+	m := s.module()
+	m.counter++
+	return fmt.Sprintf("%v%d", prefix, m.counter)
 }
 
-func (s *Scope) goBoolOp(op ast.BoolOpNumber) *jen.Statement {
-	switch op {
-	case ast.And:
-		return jen.Op("&&")
+// declare the generated variables names with the values (var at module level, where := is not allowed)
+func (s *Scope) goDefine(names []jen.Code, values ...jen.Code) *jen.Statement {
+	if s.function() == nil {
+		return jen.Var().List(names...).Op("=").List(values...)
+	}
 
-	case ast.Or:
-		return jen.Op("||")
+	return jen.List(names...).Op(":=").List(values...)
+}
+
+// the module scope
+func (s *Scope) module() *Scope {
+	curr := s
+	for curr.prev != nil {
+		curr = curr.prev
 	}
 
-	return unknown("BOOLOP", op.String())
+	return curr
 }
 
-func (s *Scope) goUnary(op ast.UnaryOpNumber) *jen.Statement {
-	switch op {
-	case ast.Not:
-		return jen.Op("!")
+// check if name is already defined, in this scope or in an enclosing one.
+//
+// In a function a name that is assigned anywhere is local to the whole function,
+// unless it's declared global or nonlocal, and it hides the names in the enclosing scopes.
+func (s *Scope) isDefined(name string) bool {
+	fn := s.function()
+	if fn != nil && (fn.names.globals[name] || fn.names.nonlocals[name]) {
+		return true
+	}
 
-	case ast.UAdd:
-		return jen.Op("+")
+	for curr := s; curr != nil; curr = curr.prev {
+		if _, ok := curr.vars[name]; ok {
+			return true
+		}
 
-	case ast.USub:
-		return jen.Op("-")
+		if curr == fn && fn.names.locals[name] {
+			return false
+		}
 	}
 
-	return unknown("UNARY", op.String())
+	return false
 }
 
-func (s *Scope) goOp(op ast.OperatorNumber) *jen.Statement {
-	return s.goOpExt(op, "")
+// check if the element in the expression list are new names
+// (and add them to the list of known names)
+func (s *Scope) newNames(lexpr []ast.Expr) (ret bool) {
+	for _, x := range lexpr {
+		for _, nn := range targetNames(x) {
+			if !s.isDefined(nn) {
+				s.vars[nn] = typeAny
+				ret = true
+			}
+		}
+	}
+
+	return
 }
 
-func (s *Scope) goOpExt(op ast.OperatorNumber, ext string) *jen.Statement {
-	switch op {
-	case ast.Add:
-		return jen.Op("+" + ext)
-	case ast.Sub:
-		return jen.Op("-" + ext)
-	case ast.Mult:
-		return jen.Op("*" + ext)
-	case ast.Div:
-		return jen.Op("/" + ext)
-	case ast.Modulo:
-		return jen.Op("%" + ext)
-	case ast.Pow:
-		return jen.Op("**" + ext)
-	case ast.LShift:
-		return jen.Op("<<" + ext)
-	case ast.RShift:
-		return jen.Op(">>" + ext)
-	case ast.BitOr:
-		return jen.Op("|" + ext)
-	case ast.BitXor:
-		return jen.Op("^" + ext)
-	case ast.BitAnd:
-		return jen.Op("&" + ext)
-	case ast.FloorDiv:
-		return jen.Op("/ /*floor*/" + ext)
+// check if all the names in the target are already defined
+func (s *Scope) allDefined(target ast.Expr) bool {
+	for _, name := range targetNames(target) {
+		if !s.isDefined(name) {
+			return false
+		}
 	}
 
-	return unknown("OP", op.String()+ext)
+	return true
 }
 
-func (s *Scope) goCmpOp(op ast.CmpOp) *jen.Statement {
-	switch op {
-	case ast.Eq:
-		return jen.Op("==")
-	case ast.NotEq:
-		return jen.Op("!=")
-	case ast.Lt:
-		return jen.Op("<")
-	case ast.LtE:
-		return jen.Op("<=")
-	case ast.Gt:
-		return jen.Op(">")
-	case ast.GtE:
-		return jen.Op(">=")
-	case ast.Is:
-		return jen.Op("==") // is
-	case ast.IsNot:
-		return jen.Op("!=") // is not
-	case ast.In:
-		return jen.Op("in")
-	case ast.NotIn:
-		return jen.Op("not in")
+func (s *Scope) addName(id ast.Identifier, t *Type) {
+	s.vars[string(id)] = t
+}
+
+// funcNames are the names bound in a function (or module) body, following python scoping rules
+type funcNames struct {
+	locals    map[string]bool       // names bound in the function
+	hoisted   []string              // locals first assigned in a nested block, declared at the top of the function
+	values    map[string][]ast.Expr // the values assigned to the locals (nil if not known)
+	loops     map[string][]loopValue // the loops with the locals as targets
+	globals   map[string]bool       // names declared global
+	nonlocals map[string]bool       // names declared nonlocal
+	widened   map[string]bool       // names divided in place (x /= y), that may not be ints anymore
+}
+
+// the names bound by an assignment target
+func targetNames(target ast.Expr) (names []string) {
+	switch t := target.(type) {
+	case *ast.Name:
+		names = append(names, string(t.Id))
+
+	case *ast.Tuple:
+		for _, x := range t.Elts {
+			names = append(names, targetNames(x)...)
+		}
+
+	case *ast.List:
+		for _, x := range t.Elts {
+			names = append(names, targetNames(x)...)
+		}
+
+	case *ast.Starred:
+		names = targetNames(t.Value)
 	}
 
-	return unknown("CMPOP", op.String())
+	return
 }
 
-func (s *Scope) goSlice(name ast.Expr, value ast.Slicer) *jen.Statement {
-	stmt := s.goExpr(name)
-	start := jen.Empty()
-	end := jen.Empty()
+// call f for each statement in body and in its nested blocks, but not in nested functions or classes
+// (nested is true for the statements in a nested block)
+func walkBody(body []ast.Stmt, nested bool, f func(stmt ast.Stmt, nested bool)) {
+	for _, stmt := range body {
+		f(stmt, nested)
 
-	exprval := func(name, val ast.Expr) *jen.Statement {
-		if unary, ok := val.(*ast.UnaryOp); ok && unary.Op == ast.USub { // -x
-			return jen.Len(s.goExpr(name)).Op("-").Add(s.goExpr(unary.Operand))
-		} else {
-			return s.goExpr(val)
+		switch v := stmt.(type) {
+		case *ast.If:
+			walkBody(v.Body, true, f)
+			walkBody(v.Orelse, true, f)
+
+		case *ast.For:
+			walkBody(v.Body, true, f)
+			walkBody(v.Orelse, true, f)
+
+		case *ast.While:
+			walkBody(v.Body, true, f)
+			walkBody(v.Orelse, true, f)
+
+		case *ast.With:
+			walkBody(v.Body, true, f)
+
+		case *ast.Try:
+			walkBody(v.Body, true, f)
+			for _, h := range v.Handlers {
+				walkBody(h.Body, true, f)
+			}
+			walkBody(v.Orelse, true, f)
+			walkBody(v.Finalbody, true, f)
 		}
 	}
+}
 
-	switch sl := value.(type) {
-	case *ast.Slice:
-		if sl.Lower != nil {
-			start = exprval(name, sl.Lower)
+// how a statement binds a name
+type binding int
+
+const (
+	bindDefinition binding = iota // a function, class or import, declared where it is defined
+	bindVariable                  // an assignment target, declared with var (or :=)
+	bindScoped                    // a loop, with or except target, declared by a Go statement with its own scope
+)
+
+// call bind for each name bound by stmt
+func stmtBindings(stmt ast.Stmt, bind func(name string, kind binding)) {
+	switch v := stmt.(type) {
+	case *ast.Assign:
+		for _, t := range v.Targets {
+			for _, name := range targetNames(t) {
+				bind(name, bindVariable)
+			}
 		}
-		if sl.Upper != nil {
-			end = exprval(name, sl.Upper)
+
+	case *ast.AugAssign:
+		for _, name := range targetNames(v.Target) {
+			bind(name, bindVariable)
 		}
-		if sl.Step != nil {
-			// if sl.Lower==nil && sl.Upper==nil && sl.Step == -1
-			// it would be a reverse slice, not that we can easily do it
 
-			log.Printf("at %v:%v", value.GetLineno(), value.GetColOffset())
-			panic("step index not implemented")
+	case *ast.For:
+		for _, name := range targetNames(v.Target) {
+			bind(name, bindScoped)
 		}
-		stmt.Add(jen.Index(start, end))
 
-	case *ast.Index:
-		stmt.Add(jen.Index(exprval(name, sl.Value)))
+	case *ast.With:
+		for _, item := range v.Items {
+			if item.OptionalVars != nil {
+				for _, name := range targetNames(item.OptionalVars) {
+					bind(name, bindScoped)
+				}
+			}
+		}
 
-	case *ast.ExtSlice: // start:stop:step
-		log.Printf("at %v:%v", value.GetLineno(), value.GetColOffset())
-		panic("ExtSlice not implemented")
-	}
+	case *ast.Try:
+		for _, h := range v.Handlers {
+			if h.Name != "" {
+				bind(string(h.Name), bindScoped)
+			}
+		}
 
-	return stmt
-}
+	case *ast.Import:
+		for _, i := range v.Names {
+			if i.AsName != "" {
+				bind(string(i.AsName), bindDefinition)
+			} else {
+				bind(string(i.Name), bindDefinition)
+			}
+		}
 
-func (s *Scope) goIdentifiers(l []ast.Identifier) *jen.Statement {
-	return jen.ListFunc(func(g *jen.Group) {
-		for _, i := range l {
-			g.Add(goId(i))
+	case *ast.ImportFrom:
+		for _, i := range v.Names {
+			if i.AsName != "" {
+				bind(string(i.AsName), bindDefinition)
+			} else {
+				bind(string(i.Name), bindDefinition)
+			}
 		}
-	})
-}
 
-func (s *Scope) strIdentifiers(l []ast.Identifier) string {
-	var sx []string
-	for _, i := range l {
-		sx = append(sx, string(i))
-	}
+	case *ast.FunctionDef:
+		bind(string(v.Name), bindDefinition)
 
-	return strings.Join(sx, ",")
+	case *ast.ClassDef:
+		bind(string(v.Name), bindDefinition)
+	}
 }
 
-func (s *Scope) goInitialized(otype *jen.Statement, values []ast.Expr) *jen.Statement {
-	return jen.Parens(otype.Clone().ValuesFunc(func(g *jen.Group) {
-		for _, v := range values {
-			g.Add(s.goExpr(v))
-		}
-	}))
-}
+// the statements where the scoped name is bound by stmt: the loop or the with statement,
+// or the handlers of a try statement that bind the exception to name
+func scopedBody(stmt ast.Stmt, name string) []ast.Stmt {
+	try, ok := stmt.(*ast.Try)
+	if !ok {
+		return []ast.Stmt{stmt}
+	}
 
-func (s *Scope) goExprList(values []ast.Expr) *jen.Statement {
-	return jen.ListFunc(func(g *jen.Group) {
-		for _, v := range values {
-			g.Add(s.goExpr(v))
+	var body []ast.Stmt
+	for _, h := range try.Handlers {
+		if string(h.Name) == name {
+			body = append(body, h.Body...)
 		}
-	})
-}
-
-func (s *Scope) strExprList(values []ast.Expr) string {
-	var sx []string
-	for _, v := range values {
-		sx = append(sx, s.goExpr(v).GoString())
 	}
 
-	return strings.Join(sx, ",")
+	return body
 }
 
-func (s *Scope) goExprOrList(expr ast.Expr) *jen.Statement {
-	if tuple, ok := expr.(*ast.Tuple); ok {
-		return s.goExpr(tuple.Elts)
+// name is used in body, outside of the statements in skip (and of the targets of other loops, that bind it again)
+func usedOutside(body, skip []ast.Stmt, name string) bool {
+	used := false
+
+	var visit func(node ast.Ast) bool
+	visit = func(node ast.Ast) bool {
+		switch v := node.(type) {
+		case *ast.Name:
+			used = used || string(v.Id) == name
+
+		case *ast.For:
+			if slices.Contains(skip, ast.Stmt(v)) {
+				return false
+			}
+
+			ast.Walk(v.Iter, visit)
+			for _, stmt := range append(v.Body, v.Orelse...) {
+				ast.Walk(stmt, visit)
+			}
+			return false
+
+		case ast.Stmt:
+			return !slices.Contains(skip, v)
+		}
+
+		return !used
 	}
-	return s.goExpr(expr)
-}
 
-func lenExpr(expr ast.Expr) int {
-	if tuple, ok := expr.(*ast.Tuple); ok {
-		return len(tuple.Elts)
+	for _, stmt := range body {
+		ast.Walk(stmt, visit)
 	}
 
-	return 1
+	return used
 }
 
-func isNone(expr ast.Expr) bool {
-	if c, ok := expr.(*ast.NameConstant); ok {
-		return c.Value == py.None
+// collect the names bound in a function body
+func newFuncNames(body []ast.Stmt) *funcNames {
+	fn := &funcNames{
+		locals:    map[string]bool{},
+		values:    map[string][]ast.Expr{},
+		loops:     map[string][]loopValue{},
+		globals:   map[string]bool{},
+		nonlocals: map[string]bool{},
+		widened:   map[string]bool{},
 	}
 
-	return false
-}
+	// global and nonlocal apply to the whole function
+	walkBody(body, false, func(stmt ast.Stmt, nested bool) {
+		switch v := stmt.(type) {
+		case *ast.Global:
+			for _, name := range v.Names {
+				fn.globals[string(name)] = true
+			}
 
-func isTuple(expr ast.Expr) bool {
-	_, ok := expr.(*ast.Tuple)
-	return ok
-}
+		case *ast.Nonlocal:
+			for _, name := range v.Names {
+				fn.nonlocals[string(name)] = true
+			}
+		}
+	})
 
-func isList(expr ast.Expr) bool {
-	_, ok := expr.(*ast.List)
-	return ok
-}
+	assigned := map[string]bool{}
 
-// check for `__name__ == "__main__"`
-func isNameMain(expr ast.Expr) bool {
-	comp, ok := expr.(*ast.Compare)
+	walkBody(body, false, func(stmt ast.Stmt, nested bool) {
+		stmtBindings(stmt, func(name string, kind binding) {
+			if fn.globals[name] || fn.nonlocals[name] {
+				return
+			}
+
+			fn.locals[name] = true
+
+			// the Go scope of a loop, with or except target ends with its statement:
+			// it is declared at the top of the function if it's used anywhere else
+			if kind == bindScoped && !assigned[name] && usedOutside(body, scopedBody(stmt, name), name) {
+				assigned[name] = true
+				fn.hoisted = append(fn.hoisted, name)
+			}
+
+			if kind == bindVariable && !assigned[name] {
+				assigned[name] = true
+				if nested {
+					fn.hoisted = append(fn.hoisted, name)
+				}
+			}
+		})
+
+		switch v := stmt.(type) {
+		case *ast.For:
+			fn.addLoopValues(v.Target, v.Iter)
+
+		case *ast.With:
+			for _, item := range v.Items {
+				if item.OptionalVars != nil {
+					fn.addValues(item.OptionalVars, item.ContextExpr)
+				}
+			}
+
+		case *ast.Try:
+			for _, h := range v.Handlers {
+				if h.Name != "" {
+					fn.values[string(h.Name)] = append(fn.values[string(h.Name)], nil)
+				}
+			}
+		}
+
+		if assign, ok := stmt.(*ast.Assign); ok {
+			for _, t := range assign.Targets {
+				fn.addValues(t, assign.Value)
+			}
+		}
+
+		if aug, ok := stmt.(*ast.AugAssign); ok && aug.Op == ast.Div {
+			if name, ok := aug.Target.(*ast.Name); ok {
+				fn.widened[string(name.Id)] = true
+			}
+		}
+	})
+
+	return fn
+}
+
+// the type of a new variable assigned a value of type t: an int variable divided
+// in place can't hold the result (a float), and becomes an Any
+func (s *Scope) variableType(name string, t *Type) *Type {
+	if fn := s.function(); fn != nil && fn.names.widened[name] && t != nil && t.Kind == KindInt {
+		return typeAny
+	}
+
+	return t
+}
+
+// a loop target is the i-th of the n targets of a loop over iter
+type loopValue struct {
+	iter ast.Expr
+	i, n int
+}
+
+// record the loop over iter for the names in target (nil values if they are patterns)
+func (fn *funcNames) addLoopValues(target, iter ast.Expr) {
+	ids, ok := exprIds(target)
 	if !ok {
-		return false
+		for _, name := range targetNames(target) {
+			fn.values[name] = append(fn.values[name], nil)
+		}
+		return
 	}
-	if len(comp.Ops) != 1 {
-		return false
+
+	for i, id := range ids {
+		fn.loops[string(id)] = append(fn.loops[string(id)], loopValue{iter: iter, i: i, n: len(ids)})
 	}
-	if comp.Ops[0] != ast.Eq {
-		return false
+}
+
+// record the values assigned to the names in target (nil if we can't tell)
+func (fn *funcNames) addValues(target, value ast.Expr) {
+	switch t := target.(type) {
+	case *ast.Name:
+		fn.values[string(t.Id)] = append(fn.values[string(t.Id)], value)
+
+	case *ast.Tuple:
+		if v, ok := value.(*ast.Tuple); ok && len(v.Elts) == len(t.Elts) {
+			for i, x := range t.Elts {
+				fn.addValues(x, v.Elts[i])
+			}
+			return
+		}
+
+		for _, name := range targetNames(t) {
+			fn.values[name] = append(fn.values[name], nil)
+		}
+	}
+}
+
+// collect the names declared global in the functions of the module, but never assigned
+// in the module scope. They need to be declared at the package level.
+func (s *Scope) collectGlobals(body []ast.Stmt) {
+	module := newFuncNames(body)
+	seen := map[string]bool{}
+
+	for _, name := range module.hoisted {
+		// first assigned in a nested block (i.e. in main)
+		module.locals[name] = false
+	}
+
+	for _, stmt := range body {
+		ast.Walk(stmt, func(node ast.Ast) bool {
+			if g, ok := node.(*ast.Global); ok {
+				for _, id := range g.Names {
+					name := string(id)
+					if !module.locals[name] && !seen[name] {
+						seen[name] = true
+						s.globals = append(s.globals, name)
+						s.addName(id, typeAny)
+					}
+				}
+			}
+
+			return true
+		})
+	}
+}
+
+// the package level declarations for the global names not defined in the module
+func (s *Scope) goGlobals() *jen.Statement {
+	if len(s.globals) == 0 {
+		return nil
+	}
+
+	return goVarDecls(s.globals, nil).Line()
+}
+
+// declare the variables in names, with the given types (Any if nil)
+func goVarDecls(names []string, types []*Type) *jen.Statement {
+	typ := func(i int) *jen.Statement {
+		if types == nil {
+			return goAny.Clone()
+		}
+		return types[i].Go()
+	}
+
+	if len(names) == 1 {
+		return jen.Var().Id(rename(names[0])).Add(typ(0))
+	}
+
+	return jen.Var().DefsFunc(func(g *jen.Group) {
+		for i, name := range names {
+			g.Id(rename(name)).Add(typ(i))
+		}
+	})
+}
+
+// check that the names declared nonlocal in fdef are defined in the enclosing function
+func (s *Scope) checkNonlocals(fdef *ast.FunctionDef, names *funcNames) {
+	if len(names.nonlocals) == 0 {
+		return
+	}
+
+	fn := s.function()
+	if fn == nil {
+		errorf(fdef, "nonlocal declaration not allowed at module level")
+		return
+	}
+
+	for name := range names.nonlocals {
+		if !fn.isDefined(name) && !fn.names.locals[name] {
+			errorf(fdef, "no binding for nonlocal %q found", name)
+		}
+	}
+}
+
+// declare the variables that are first assigned in a nested block at the top of the function,
+// since in python they are visible in the whole function.
+// This is also where we check for variables read before being assigned.
+func (s *Scope) goHoisted(body []ast.Stmt) *jen.Statement {
+	assigned := map[string]bool{}
+	for name := range s.vars { // the parameters
+		assigned[name] = true
+	}
+
+	s.names.checkUnbound(body, assigned)
+
+	var hoisted []string
+	var types []*Type
+
+	for _, name := range s.names.hoisted {
+		if _, ok := s.vars[name]; !ok {
+			t := s.variableType(name, s.hoistedType(name))
+			hoisted = append(hoisted, name)
+			types = append(types, t)
+			s.vars[name] = t
+		}
+	}
+
+	if len(hoisted) == 0 {
+		return jen.Null()
+	}
+
+	return goVarDecls(hoisted, types).Line()
+}
+
+// the type of a hoisted variable: the common type of the values assigned to it
+// and of the elements of the loops with the variable as target
+func (s *Scope) hoistedType(name string) *Type {
+	values, loops := s.names.values[name], s.names.loops[name]
+	if len(loops) == 0 {
+		return s.valuesType(values)
+	}
+
+	t := s.forTypes(loops[0].n, loops[0].iter)[loops[0].i]
+	for _, l := range loops[1:] {
+		t = unify(t, s.forTypes(l.n, l.iter)[l.i])
+	}
+	if len(values) > 0 {
+		t = unify(t, s.valuesType(values))
+	}
+
+	if !t.Known() {
+		return typeAny
+	}
+
+	return t
+}
+
+// the common type of the values assigned to a variable (Any if they are not all the same)
+func (s *Scope) valuesType(values []ast.Expr) *Type {
+	if len(values) == 0 {
+		return typeAny
+	}
+
+	var t *Type
+
+	for i, v := range values {
+		vt := typeAny
+		if v != nil {
+			vt = s.exprType(v)
+		}
+
+		if i == 0 {
+			t = vt
+		} else {
+			t = unify(t, vt)
+		}
+	}
+
+	if !t.Known() {
+		return typeAny
+	}
+
+	return t
+}
+
+// call f for the names read in expr
+// (but not in lambdas and comprehensions, that have their own scope)
+func readNames(expr ast.Expr, f func(name *ast.Name)) {
+	if expr == nil {
+		return
+	}
+
+	ast.Walk(expr, func(node ast.Ast) bool {
+		switch v := node.(type) {
+		case *ast.Name:
+			f(v)
+
+		case *ast.Lambda, *ast.ListComp, *ast.SetComp, *ast.DictComp, *ast.GeneratorExp:
+			return false
+		}
+
+		return true
+	})
+}
+
+// call f for the names read in an assignment target (i.e. a[i] reads a and i)
+func targetReads(target ast.Expr, f func(name *ast.Name)) {
+	switch t := target.(type) {
+	case *ast.Name:
+
+	case *ast.Tuple:
+		for _, x := range t.Elts {
+			targetReads(x, f)
+		}
+
+	case *ast.List:
+		for _, x := range t.Elts {
+			targetReads(x, f)
+		}
+
+	case *ast.Starred:
+		targetReads(t.Value, f)
+
+	default:
+		readNames(target, f)
+	}
+}
+
+func copyNames(names map[string]bool) map[string]bool {
+	c := make(map[string]bool, len(names))
+	for k, v := range names {
+		c[k] = v
+	}
+
+	return c
+}
+
+// report the local names that are read before any possible assignment
+// (an UnboundLocalError in python). assigned are the names that may be assigned at this point.
+func (fn *funcNames) checkUnbound(body []ast.Stmt, assigned map[string]bool) {
+	read := func(name *ast.Name) {
+		if n := string(name.Id); fn.locals[n] && !assigned[n] {
+			errorf(name, "local variable %q referenced before assignment", n)
+			assigned[n] = true // report only once
+		}
+	}
+
+	reads := func(expr ast.Expr) {
+		readNames(expr, read)
+	}
+
+	bind := func(stmt ast.Stmt) {
+		stmtBindings(stmt, func(name string, kind binding) {
+			assigned[name] = true
+		})
+	}
+
+	// in a loop, the names assigned in the body may have been assigned in a previous iteration
+	loop := func(body []ast.Stmt) {
+		walkBody(body, true, func(stmt ast.Stmt, nested bool) {
+			bind(stmt)
+		})
+	}
+
+	// the names possibly assigned in a nested block
+	block := func(body []ast.Stmt) map[string]bool {
+		nested := copyNames(assigned)
+		fn.checkUnbound(body, nested)
+		return nested
+	}
+
+	merge := func(names map[string]bool) {
+		for n := range names {
+			assigned[n] = true
+		}
+	}
+
+	for _, stmt := range body {
+		switch v := stmt.(type) {
+		case *ast.Assign:
+			reads(v.Value)
+			for _, t := range v.Targets {
+				targetReads(t, read)
+			}
+
+		case *ast.AugAssign:
+			reads(v.Target)
+			reads(v.Value)
+
+		case *ast.ExprStmt:
+			reads(v.Value)
+
+		case *ast.Return:
+			reads(v.Value)
+
+		case *ast.Raise:
+			reads(v.Exc)
+			reads(v.Cause)
+
+		case *ast.Assert:
+			reads(v.Test)
+			reads(v.Msg)
+
+		case *ast.Delete:
+			for _, t := range v.Targets {
+				targetReads(t, read)
+			}
+
+		case *ast.If:
+			reads(v.Test)
+			merge(block(v.Body))
+			merge(block(v.Orelse))
+
+		case *ast.For:
+			reads(v.Iter)
+			bind(v)
+			loop(v.Body)
+			merge(block(v.Body))
+			merge(block(v.Orelse))
+
+		case *ast.While:
+			loop(v.Body)
+			reads(v.Test)
+			merge(block(v.Body))
+			merge(block(v.Orelse))
+
+		case *ast.With:
+			for _, item := range v.Items {
+				reads(item.ContextExpr)
+			}
+			bind(v)
+			merge(block(v.Body))
+
+		case *ast.Try:
+			// an exception can be raised anywhere in the body
+			loop(v.Body)
+			merge(block(v.Body))
+			for _, h := range v.Handlers {
+				nested := copyNames(assigned)
+				if h.Name != "" {
+					nested[string(h.Name)] = true
+				}
+				fn.checkUnbound(h.Body, nested)
+				merge(nested)
+			}
+			merge(block(v.Orelse))
+			merge(block(v.Finalbody))
+		}
+
+		bind(stmt)
+	}
+}
+
+// Signature describes the parameters of a function or method defined in the module.
+//
+// It is used to translate calls that omit default arguments or pass them by keyword,
+// since Go doesn't have either.
+type Signature struct {
+	name     string     // Go name of the function (for methods, Class.method)
+	required []*ast.Arg // parameters without a default value
+	optional []*ast.Arg // parameters with a default value (positional first, then keyword only)
+	defaults []ast.Expr // default values of the optional parameters
+	nreq     int        // number of required parameters that can be passed by position
+	npos     int        // number of optional parameters that can be passed by position
+	vararg   *ast.Arg   // *args
+	kwarg    *ast.Arg   // **kwargs
+	returns  *Type      // the type of the return value, if annotated
+	results  []*Type    // the types of the Go results, for functions that always return a tuple of 2 or more values
+}
+
+func newSignature(name string, fdef *ast.FunctionDef, skipReceiver bool) *Signature {
+	sig := &Signature{name: name}
+	if elts := tupleAnnotation(fdef.Returns); len(elts) > 1 { // -> Tuple[int, str]
+		sig.returns = typeTuple
+		for _, elt := range elts {
+			sig.results = append(sig.results, annotationType(elt))
+		}
+	} else if fdef.Returns != nil {
+		sig.returns = annotationType(fdef.Returns)
+	} else if n := tupleResults(fdef.Body); n > 0 {
+		// the types are inferred from the values returned
+		sig.results = make([]*Type, n)
+	}
+
+	args := fdef.Args
+	if args == nil {
+		return sig
+	}
+
+	sig.vararg = args.Vararg
+	sig.kwarg = args.Kwarg
+
+	aargs := args.Args
+	if skipReceiver && len(aargs) > 0 {
+		aargs = aargs[1:]
+	}
+
+	// defaults are for the last len(args.Defaults) positional parameters
+	nreq := len(aargs) - len(args.Defaults)
+	if nreq < 0 {
+		nreq = 0
+	}
+
+	sig.required = append(sig.required, aargs[:nreq]...)
+	sig.nreq = nreq
+	sig.optional = append(sig.optional, aargs[nreq:]...)
+	sig.defaults = append(sig.defaults, args.Defaults[len(args.Defaults)-len(sig.optional):]...)
+	sig.npos = len(sig.optional)
+
+	// keyword only parameters with no default are required
+	for i, arg := range args.Kwonlyargs {
+		if i < len(args.KwDefaults) && args.KwDefaults[i] != nil {
+			sig.optional = append(sig.optional, arg)
+			sig.defaults = append(sig.defaults, args.KwDefaults[i])
+		} else {
+			sig.required = append(sig.required, arg)
+		}
+	}
+
+	return sig
+}
+
+// the types of the elements of a Tuple[t1, t2, ...] annotation (nil if ann is not a tuple of fixed length)
+func tupleAnnotation(ann ast.Expr) []ast.Expr {
+	sub, ok := ann.(*ast.Subscript)
+	if !ok {
+		return nil
+	}
+
+	name, ok := sub.Value.(*ast.Name)
+	index, iok := sub.Slice.(*ast.Index)
+	if !ok || !iok || (name.Id != "tuple" && name.Id != "Tuple") {
+		return nil
+	}
+
+	elts, ok := index.Value.(*ast.Tuple)
+	if !ok {
+		return nil
+	}
+
+	for _, elt := range elts.Elts {
+		if _, ok := elt.(*ast.Ellipsis); ok { // Tuple[int, ...]
+			return nil
+		}
+	}
+
+	return elts.Elts
+}
+
+// the Go results of a function returning multiple values
+func (sig *Signature) goResults() *jen.Statement {
+	return jen.ParamsFunc(func(g *jen.Group) {
+		for _, t := range sig.results {
+			g.Add(t.Go())
+		}
+	})
+}
+
+// add the types of the values returned by return (a tuple) to the results of a function
+// that returns multiple values, when they are not annotated
+func (s *Scope) addResults(sig *Signature, ret *ast.Tuple) {
+	if sig.returns != nil {
+		return
 	}
 
-	name, ok := comp.Left.(*ast.Name)
-	if !ok {
-		return false
+	for i, elt := range ret.Elts {
+		if t := s.exprType(elt); sig.results[i] == nil {
+			sig.results[i] = t
+		} else {
+			sig.results[i] = unify(sig.results[i], t)
+		}
+	}
+}
+
+// return value from a function with multiple results: the elements of a tuple, the results of
+// a call that returns the same number of values, or the elements of value unpacked at runtime
+func (s *Scope) goReturnResults(sig *Signature, value ast.Expr) *jen.Statement {
+	if tuple, ok := value.(*ast.Tuple); ok && len(tuple.Elts) == len(sig.results) && starredIndex(tuple.Elts) < 0 {
+		s.addResults(sig, tuple)
+		return jen.Return(s.goExprList(tuple.Elts))
+	}
+
+	if types := s.callResults(value); len(types) == len(sig.results) {
+		return jen.Return(s.goCall(value.(*ast.Call)))
+	}
+
+	tuple := s.generatedName("_t")
+	return jen.Id(tuple).Op(":=").Qual(goRuntime, "Unpack").Call(s.goExpr(value), jen.Lit(len(sig.results))).Line().
+		ReturnFunc(func(g *jen.Group) {
+			for i, t := range sig.results {
+				if item := jen.Id(tuple).Index(jen.Lit(i)); t.Known() {
+					g.Add(item.Assert(t.Go()))
+				} else {
+					g.Add(item)
+				}
+			}
+		})
+}
+
+// the number of values returned by a function body where every return statement returns
+// a tuple with the same number (at least 2) of values, 0 otherwise
+func tupleResults(body []ast.Stmt) (n int) {
+	walkBody(body, false, func(stmt ast.Stmt, nested bool) {
+		if x, ok := stmt.(*ast.ExprStmt); ok {
+			switch x.Value.(type) {
+			case *ast.Yield, *ast.YieldFrom: // a generator
+				n = -1
+			}
+		}
+
+		ret, ok := stmt.(*ast.Return)
+		if !ok || n < 0 {
+			return
+		}
+
+		if tuple, ok := ret.Value.(*ast.Tuple); ok && len(tuple.Elts) > 1 && starredIndex(tuple.Elts) < 0 &&
+			(n == 0 || n == len(tuple.Elts)) {
+			n = len(tuple.Elts)
+		} else {
+			n = -1
+		}
+	})
+
+	if n < 0 || !terminates(body) { // falling off the end returns None
+		return 0
+	}
+
+	return n
+}
+
+// every path of the body ends with a return or a raise
+func terminates(body []ast.Stmt) bool {
+	if len(body) == 0 {
+		return false
+	}
+
+	switch last := body[len(body)-1].(type) {
+	case *ast.Return, *ast.Raise:
+		return true
+
+	case *ast.If:
+		return terminates(last.Body) && terminates(last.Orelse)
+	}
+
+	return false
+}
+
+// the name of the struct type holding the optional arguments
+func (sig *Signature) argsType() string {
+	return strings.Replace(sig.name, ".", "_", -1) + "Args"
+}
+
+// the name of the variable holding the default values for the optional arguments
+func (sig *Signature) defaultsVar() string {
+	return strings.Replace(sig.name, ".", "_", -1) + "Defaults"
+}
+
+// the name of the function parameter holding the optional arguments
+func (sig *Signature) argsParam() string {
+	for _, arg := range sig.required {
+		if string(arg.Arg) == "opts" {
+			return "optsΠ"
+		}
+	}
+	for _, arg := range sig.optional {
+		if string(arg.Arg) == "opts" {
+			return "optsΠ"
+		}
+	}
+
+	return "opts"
+}
+
+// the index of the required parameter with the given name (or -1)
+func (sig *Signature) requiredIndex(name ast.Identifier) int {
+	for i, arg := range sig.required {
+		if arg.Arg == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// the index of the optional parameter with the given name (or -1)
+func (sig *Signature) optionalIndex(name ast.Identifier) int {
+	for i, arg := range sig.optional {
+		if arg.Arg == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// collect the signatures of the functions and methods defined in body,
+// so that calls can be translated before the definition is parsed
+func (s *Scope) collectSignatures(classname string, body []ast.Stmt) {
+	for _, stmt := range body {
+		switch v := stmt.(type) {
+		case *ast.FunctionDef:
+			name := string(v.Name)
+			if classname != "" {
+				name = classname + "." + name
+			}
+			s.funcs[name] = newSignature(name, v, classname != "")
+
+		case *ast.ClassDef:
+			s.classes[string(v.Name)] = true
+			s.collectSignatures(string(v.Name), v.Body)
+		}
+	}
+}
+
+// the type of a parameter: the annotation if present, the type of the default value
+// if it's a literal or Any
+func paramType(arg *ast.Arg, def ast.Expr) *Type {
+	if arg.Annotation != nil {
+		return annotationType(arg.Annotation)
+	}
+
+	if def != nil {
+		return literalType(def)
+	}
+
+	return typeAny
+}
+
+// the Go type of a parameter (see paramType)
+func (s *Scope) goParamType(arg *ast.Arg, def ast.Expr) *jen.Statement {
+	if t := paramType(arg, def); t.Known() || arg.Annotation == nil {
+		return t.Go()
+	}
+
+	return s.goExpr(arg.Annotation)
+}
+
+// the declarations needed for the optional arguments of a function:
+// a struct type with the arguments and a function returning the default values
+func (s *Scope) goDefaults(sig *Signature, top bool) *jen.Statement {
+	argsType := jen.Id(sig.argsType()).StructFunc(func(g *jen.Group) {
+		for i, arg := range sig.optional {
+			g.Add(goId(arg.Arg).Add(s.goParamType(arg, sig.defaults[i])))
+		}
+	})
+
+	// as in python, the default values are evaluated once, when the function is defined
+	defaults := jen.Id(sig.argsType()).ValuesFunc(func(g *jen.Group) {
+		for i, arg := range sig.optional {
+			g.Add(goId(arg.Arg).Op(":").Add(s.goExprAs(sig.defaults[i], paramType(arg, sig.defaults[i]))))
+		}
+	})
+
+	if top {
+		return jen.Commentf("%v holds the optional arguments of %v", sig.argsType(), sig.name).Line().
+			Type().Add(argsType).Line().Line().
+			Commentf("%v holds the default values of the optional arguments of %v", sig.defaultsVar(), sig.name).Line().
+			Var().Id(sig.defaultsVar()).Op("=").Add(defaults).Line().Line()
+	}
+
+	return jen.Type().Add(argsType).Line().
+		Id(sig.defaultsVar()).Op(":=").Add(defaults).Line().
+		Id("_").Op("=").Id(sig.defaultsVar()).Line() // not used if the function is always called with all the arguments
+}
+
+// the signature of the function or method called by call, if defined in the module
+func (s *Scope) callSignature(call *ast.Call) *Signature {
+	switch f := call.Func.(type) {
+	case *ast.Name:
+		return s.lookupFunc(string(f.Id))
+
+	case *ast.Attribute:
+		if name, ok := f.Value.(*ast.Name); ok {
+			if string(name.Id) == "self" && s.class != "" {
+				if sig := s.lookupFunc(s.class + "." + string(f.Attr)); sig != nil {
+					return sig
+				}
+			}
+
+			if _, ok := s.imports[string(name.Id)]; ok {
+				return nil
+			}
+		}
+
+		// a method of an object of a class of the module
+		// (the signature of a method called on a value of unknown type is not known)
+		if t := s.exprType(f.Value); t.Kind == KindObject {
+			return s.lookupFunc(t.Class + "." + string(f.Attr))
+		}
+	}
+
+	return nil
+}
+
+// the arguments for a call to a function defined in the module, in the order of the definition:
+// the required arguments, a struct with the optional ones (using the default value for
+// the missing ones), a dictionary with the extra keyword arguments (for **kwargs)
+// and the extra positional arguments (for *args).
+func (s *Scope) goSignatureArgs(sig *Signature, call *ast.Call) []jen.Code {
+	var args []jen.Code
+	var rest []ast.Expr
+	var extra []*ast.Keyword
+
+	req := make([]ast.Expr, len(sig.required))
+	opts := make([]ast.Expr, len(sig.optional))
+	set := false
+
+	for i, arg := range call.Args {
+		if i < sig.nreq {
+			req[i] = arg
+		} else if j := i - sig.nreq; j < sig.npos {
+			opts[j] = arg
+			set = true
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+
+	if len(rest) > 0 && sig.vararg == nil {
+		errorf(call, "%v() takes %d positional arguments but %d were given",
+			sig.name, sig.nreq+sig.npos, len(call.Args))
+	}
+
+	for _, k := range call.Keywords {
+		if i := sig.requiredIndex(k.Arg); i >= 0 {
+			if req[i] != nil {
+				errorf(call, "%v() got multiple values for argument %q", sig.name, k.Arg)
+			}
+			req[i] = k.Value
+		} else if i := sig.optionalIndex(k.Arg); i >= 0 {
+			if opts[i] != nil {
+				errorf(call, "%v() got multiple values for argument %q", sig.name, k.Arg)
+			}
+			opts[i] = k.Value
+			set = true
+		} else if sig.kwarg != nil {
+			extra = append(extra, k)
+		} else {
+			errorf(call, "%v() got an unexpected keyword argument %q", sig.name, k.Arg)
+		}
+	}
+
+	if call.Starargs != nil && (sig.vararg == nil || len(call.Args) < sig.nreq+sig.npos) {
+		errorf(call, "%v(): *%v can only be passed as the variable arguments", sig.name,
+			s.goExpr(call.Starargs).GoString())
+	}
+
+	if call.Kwargs != nil && sig.kwarg == nil {
+		errorf(call, "%v(): **%v can only be passed as the variable keyword arguments", sig.name,
+			s.goExpr(call.Kwargs).GoString())
+	}
+
+	for i, arg := range req {
+		if arg == nil {
+			errorf(call, "%v() missing required argument %q", sig.name, sig.required[i].Arg)
+			args = append(args, jen.Nil())
+		} else {
+			args = append(args, s.goExprAs(arg, paramType(sig.required[i], nil)))
+		}
+	}
+
+	if len(sig.optional) > 0 {
+		if set {
+			args = append(args, jen.Id(sig.argsType()).ValuesFunc(func(g *jen.Group) {
+				for i, arg := range sig.optional {
+					if v := opts[i]; v != nil {
+						g.Add(goId(arg.Arg).Op(":").Add(s.goExprAs(v, paramType(arg, sig.defaults[i]))))
+					} else {
+						g.Add(goId(arg.Arg).Op(":").Id(sig.defaultsVar()).Dot(rename(string(arg.Arg))))
+					}
+				}
+			}))
+		} else {
+			args = append(args, jen.Id(sig.defaultsVar()))
+		}
+	}
+
+	if sig.kwarg != nil {
+		args = append(args, s.goKwargs(kwargsOf(paramType(sig.kwarg, nil)), extra, call.Kwargs))
+	}
+
+	if call.Starargs != nil {
+		args = append(args, s.goStarargs(rest, call.Starargs))
+	} else {
+		for _, arg := range rest {
+			args = append(args, s.goExpr(arg))
+		}
+	}
+
+	return args
+}
+
+// the dictionary of keyword arguments for a function with **kwargs (of type t: a runtime.Dict,
+// or a map[string]T if **kwargs is annotated)
+func (s *Scope) goKwargs(t *Type, keywords []*ast.Keyword, kwargs ast.Expr) *jen.Statement {
+	if len(keywords) == 0 && kwargs == nil {
+		return jen.Nil()
+	}
+
+	dict := t.Go().Values(jen.DictFunc(func(d jen.Dict) {
+		for _, k := range keywords {
+			d[jen.Lit(string(k.Arg))] = s.goExprAs(k.Value, t.Elem)
+		}
+	}))
+
+	if kwargs == nil {
+		return dict
+	}
+
+	mapping := s.goExpr(kwargs)
+	kt := s.exprType(kwargs)
+
+	if t.Elem.Known() { // the items are converted to the annotated type
+		items := jen.Qual("maps", "All").Call(mapping)
+		if kt.ordered() {
+			items = mapping.Dot("All").Call()
+		}
+		return jen.Qual(goRuntime, "MapUpdate").Call(dict, items)
+	}
+
+	if kt.ordered() {
+		mapping.Dot("ToDict").Call()
+	}
+
+	if len(keywords) == 0 {
+		return mapping
+	}
+
+	return jen.Qual(goRuntime, "MergeDicts").Call(dict, mapping)
+}
+
+// expand *seq as the last (variadic) argument, after the extra positional arguments
+func (s *Scope) goStarargs(rest []ast.Expr, starargs ast.Expr) *jen.Statement {
+	if len(rest) == 0 {
+		return s.goExpr(starargs).Op("...")
+	}
+
+	return jen.Append(s.goInitialized(goList, rest), s.goExpr(starargs).Op("...")).Op("...")
+}
+
+func (s *Scope) goBoolOp(op ast.BoolOpNumber) *jen.Statement {
+	switch op {
+	case ast.And:
+		return jen.Op("&&")
+
+	case ast.Or:
+		return jen.Op("||")
+	}
+
+	return unknown("BOOLOP", op.String())
+}
+
+func (s *Scope) goUnary(op ast.UnaryOpNumber) *jen.Statement {
+	switch op {
+	case ast.Not:
+		return jen.Op("!")
+
+	case ast.UAdd:
+		return jen.Op("+")
+
+	case ast.USub:
+		return jen.Op("-")
+	}
+
+	return unknown("UNARY", op.String())
+}
+
+func (s *Scope) goOp(op ast.OperatorNumber) *jen.Statement {
+	return s.goOpExt(op, "")
+}
+
+func (s *Scope) goOpExt(op ast.OperatorNumber, ext string) *jen.Statement {
+	switch op {
+	case ast.Add:
+		return jen.Op("+" + ext)
+	case ast.Sub:
+		return jen.Op("-" + ext)
+	case ast.Mult:
+		return jen.Op("*" + ext)
+	case ast.Div:
+		return jen.Op("/" + ext)
+	case ast.Modulo:
+		return jen.Op("%" + ext)
+	case ast.Pow:
+		return jen.Op("**" + ext)
+	case ast.LShift:
+		return jen.Op("<<" + ext)
+	case ast.RShift:
+		return jen.Op(">>" + ext)
+	case ast.BitOr:
+		return jen.Op("|" + ext)
+	case ast.BitXor:
+		return jen.Op("^" + ext)
+	case ast.BitAnd:
+		return jen.Op("&" + ext)
+	case ast.FloorDiv:
+		return jen.Op("/ /*floor*/" + ext)
+	}
+
+	return unknown("OP", op.String()+ext)
+}
+
+// the runtime functions implementing python operators on values of unknown type
+var runtimeOps = map[ast.OperatorNumber]string{
+	ast.Add:      "Add",
+	ast.Sub:      "Sub",
+	ast.Mult:     "Mul",
+	ast.Div:      "TrueDiv",
+	ast.FloorDiv: "FloorDiv",
+	ast.Modulo:   "Mod",
+	ast.Pow:      "Pow",
+}
+
+var runtimeCmps = map[ast.CmpOp]string{
+	ast.Eq:    "Eq",
+	ast.NotEq: "Ne",
+	ast.Lt:    "Lt",
+	ast.LtE:   "Le",
+	ast.Gt:    "Gt",
+	ast.GtE:   "Ge",
+}
+
+// the operation needs the runtime helpers, since we don't know the type of some of the operands
+func (s *Scope) dynamic(exprs ...ast.Expr) bool {
+	for _, expr := range exprs {
+		if s.exprType(expr).Kind == KindAny {
+			return true
+		}
+	}
+
+	return false
+}
+
+// a binary operation: Go operators when the operand types are known, runtime helpers otherwise
+func (s *Scope) goBinOp(left ast.Expr, op ast.OperatorNumber, right ast.Expr) *jen.Statement {
+	stmt, _ := s.goBinOpPrec(left, op, right)
+	return stmt
+}
+
+// the set methods implementing the python operators and comparisons on sets
+var setOps = map[ast.OperatorNumber]string{
+	ast.BitOr:  "Union",
+	ast.BitAnd: "Intersection",
+	ast.Sub:    "Difference",
+	ast.BitXor: "SymmetricDifference",
+}
+
+var setCmps = map[ast.CmpOp]string{
+	ast.Eq:    "Equal",
+	ast.NotEq: "Equal",
+	ast.Lt:    "IsProperSubset",
+	ast.LtE:   "IsSubset",
+	ast.Gt:    "IsProperSuperset",
+	ast.GtE:   "IsSuperset",
+}
+
+// the python set methods (and the Go methods implementing them)
+var setMethods = map[string]string{
+	"add":                  "Add",
+	"discard":              "Discard",
+	"remove":               "Remove",
+	"pop":                  "Pop",
+	"clear":                "Clear",
+	"copy":                 "Copy",
+	"update":               "Update",
+	"union":                "Union",
+	"intersection":         "Intersection",
+	"difference":           "Difference",
+	"symmetric_difference": "SymmetricDifference",
+	"issubset":             "IsSubset",
+	"issuperset":           "IsSuperset",
+	"isdisjoint":           "IsDisjoint",
+}
+
+// the set methods that update the set in place, with the corresponding operations
+var setUpdateMethods = map[string]string{
+	"intersection_update":         "Intersection",
+	"difference_update":           "Difference",
+	"symmetric_difference_update": "SymmetricDifference",
+}
+
+// the types returned by set methods (nil for the set type)
+var setMethodTypes = map[string]*Type{
+	"copy":                 nil,
+	"union":                nil,
+	"intersection":         nil,
+	"difference":           nil,
+	"symmetric_difference": nil,
+	"issubset":             typeBool,
+	"issuperset":           typeBool,
+	"isdisjoint":           typeBool,
+}
+
+// the runtime.Int methods implementing the python operators (with -bigint)
+var bigIntMethods = map[ast.OperatorNumber]string{
+	ast.Add:      "Add",
+	ast.Sub:      "Sub",
+	ast.Mult:     "Mul",
+	ast.Div:      "TrueDiv",
+	ast.FloorDiv: "FloorDiv",
+	ast.Modulo:   "Mod",
+	ast.Pow:      "Pow",
+	ast.BitAnd:   "And",
+	ast.BitOr:    "Or",
+	ast.BitXor:   "Xor",
+	ast.LShift:   "Lsh",
+	ast.RShift:   "Rsh",
+}
+
+// the Go precedence of a binary operator (0 if the operation is translated to a function call)
+func goPrecedence(op ast.OperatorNumber) int {
+	switch op {
+	case ast.Mult, ast.Div, ast.Modulo, ast.LShift, ast.RShift, ast.BitAnd:
+		return 5
+	case ast.Add, ast.Sub, ast.BitOr, ast.BitXor:
+		return 4
+	}
+
+	return 0
+}
+
+// the integer value of a constant expression (n or -n)
+func intConst(expr ast.Expr) (int, bool) {
+	if unary, ok := expr.(*ast.UnaryOp); ok && unary.Op == ast.USub {
+		n, ok := intConst(unary.Operand)
+		return -n, ok
+	}
+
+	if num, ok := expr.(*ast.Num); ok {
+		if n, ok := num.N.(py.Int); ok {
+			return int(n), true
+		}
+	}
+
+	return 0, false
+}
+
+// an integer power of constants that doesn't fit an int (by repeated squaring, as runtime.PowInt)
+func powOverflows(base, exp int) bool {
+	result := 1
+
+	for exp > 0 {
+		if exp&1 != 0 {
+			if r := result * base; base != 0 && r/base != result {
+				return true
+			} else {
+				result = r
+			}
+		}
+
+		exp >>= 1
+		if exp > 0 {
+			if b := base * base; base != 0 && b/base != base {
+				return true
+			} else {
+				base = b
+			}
+		}
+	}
+
+	return false
+}
+
+// the Go binary operation and its precedence (0 for function calls)
+func (s *Scope) goBinOpPrec(left ast.Expr, op ast.OperatorNumber, right ast.Expr) (*jen.Statement, int) {
+	if fn, ok := runtimeOps[op]; ok && s.dynamic(left, right) {
+		return jen.Qual(goRuntime, fn).Call(s.goExpr(left), s.goExpr(right)), 0
+	}
+
+	lt, rt := s.exprType(left), s.exprType(right)
+	ints := lt.Numeric() && rt.Numeric() && numericType(op, lt, rt).Kind == KindInt
+	floats := lt.Numeric() && rt.Numeric() && lt.Kind != KindComplex && rt.Kind != KindComplex && !ints
+	prec := goPrecedence(op)
+
+	// the Go operand: operations with lower precedence need parentheses (the python ast has none)
+	// and int values are converted to float64 when the result is a float
+	operand := func(expr ast.Expr, t *Type, isRight bool) *jen.Statement {
+		if n, ok := intConst(expr); ok && floats { // a float constant: 1 / 2 is an integer division in Go
+			return jen.Lit(float64(n))
+		}
+
+		if !floats || t.Kind == KindFloat {
+			if binop, ok := expr.(*ast.BinOp); ok {
+				stmt, p := s.goBinOpPrec(binop.Left, binop.Op, binop.Right)
+				if p != 0 && (p < prec || isRight && p == prec) {
+					stmt = jen.Parens(stmt)
+				}
+				return stmt
+			}
+
+			return s.goExpr(expr)
+		}
+
+		if bigInts && t.Kind == KindInt {
+			return s.goExpr(expr).Dot("Float64").Call()
+		}
+
+		return jen.Float64().Call(s.goExpr(expr))
+	}
+
+	if m, ok := setOps[op]; ok && lt.Kind == KindSet && rt.Kind == KindSet {
+		return s.goExpr(left).Dot(m).Call(s.goExpr(right)), 0
+	}
+
+	if op == ast.BitOr && lt.ordered() {
+		return s.goExpr(left).Dot("Merge").Call(s.goExpr(right)), 0
+	}
+
+	if op == ast.Add && lt.Kind == KindList && rt.Kind == KindList {
+		if lt.Equal(rt) {
+			return jen.Qual("slices", "Concat").Call(s.goExpr(left), s.goExpr(right)), 0
+		}
+		return jen.Qual("slices", "Concat").Call(jen.Qual(goRuntime, "ListFrom").Call(s.goExpr(left)),
+			jen.Qual(goRuntime, "ListFrom").Call(s.goExpr(right))), 0
+	}
+
+	if op == ast.Mult && (lt.Kind == KindList && rt.Kind == KindInt || lt.Kind == KindInt && rt.Kind == KindList) {
+		l, n := left, right
+		if lt.Kind == KindInt {
+			l, n = right, left
+		}
+
+		// python repeats a negative number of times as 0
+		count := s.goIntValue(n)
+		if c, ok := intConst(n); ok {
+			count = jen.Lit(max(0, c))
+		} else {
+			count = jen.Id("max").Call(jen.Lit(0), count)
+		}
+		return jen.Qual("slices", "Repeat").Call(s.goExpr(l), count), 0
+	}
+
+	if bigInts && lt.Kind == KindInt && rt.Kind == KindInt {
+		if exp, ok := intConst(right); !(ok && op == ast.Pow && exp < 0) {
+			if m, ok := bigIntMethods[op]; ok {
+				return s.goExpr(left).Dot(m).Call(s.goExpr(right)), 0
+			}
+		}
+	}
+
+	switch op {
+	case ast.Div: // true division
+		if floats {
+			return operand(left, lt, false).Op("/").Add(operand(right, rt, true)), prec
+		}
+
+	case ast.FloorDiv:
+		if ints {
+			return jen.Qual(goRuntime, "FloorDivInt").Call(s.goExpr(left), s.goExpr(right)), 0
+		}
+		if floats {
+			return jen.Qual("math", "Floor").Call(operand(left, lt, false).Op("/").Add(operand(right, rt, true))), 0
+		}
+
+	case ast.Modulo:
+		if ints {
+			return jen.Qual(goRuntime, "ModInt").Call(s.goExpr(left), s.goExpr(right)), 0
+		}
+		if floats {
+			return jen.Qual(goRuntime, "ModFloat").Call(operand(left, lt, false), operand(right, rt, true)), 0
+		}
+
+	case ast.Pow:
+		if ints {
+			base, bok := intConst(left)
+			exp, eok := intConst(right)
+
+			switch {
+			case eok && exp < 0: // a negative exponent gives a float
+				floats = true
+				return jen.Qual("math", "Pow").Call(operand(left, lt, false), operand(right, rt, true)), 0
+
+			case bok && eok && powOverflows(base, exp):
+				return jen.Qual(goRuntime, "BigPow").Call(s.goExpr(left), s.goExpr(right)), 0
+
+			case bok && eok:
+				return jen.Qual(goRuntime, "PowInt").Call(s.goExpr(left), s.goExpr(right)), 0
+			}
+
+			// an int (PowInt raises an OverflowError if the result doesn't fit)
+			return jen.Qual(goRuntime, "PowInt").Call(s.goExpr(left), s.goExpr(right)), 0
+		}
+
+		if lt.Kind == KindComplex || rt.Kind == KindComplex {
+			return jen.Qual("math/cmplx", "Pow").Call(s.goExpr(left), s.goExpr(right)), 0
+		}
+
+		return jen.Qual("math", "Pow").Call(operand(left, lt, false), operand(right, rt, true)), 0
+	}
+
+	return operand(left, lt, false).Add(s.goOp(op)).Add(operand(right, rt, true)), prec
+}
+
+func (s *Scope) goCmpOp(op ast.CmpOp) *jen.Statement {
+	switch op {
+	case ast.Eq:
+		return jen.Op("==")
+	case ast.NotEq:
+		return jen.Op("!=")
+	case ast.Lt:
+		return jen.Op("<")
+	case ast.LtE:
+		return jen.Op("<=")
+	case ast.Gt:
+		return jen.Op(">")
+	case ast.GtE:
+		return jen.Op(">=")
+	case ast.Is:
+		return jen.Op("==") // is
+	case ast.IsNot:
+		return jen.Op("!=") // is not
+	case ast.In:
+		return jen.Op("in")
+	case ast.NotIn:
+		return jen.Op("not in")
+	}
+
+	return unknown("CMPOP", op.String())
+}
+
+// if expr is d[k], with d a runtime.OrderedDict, return d and k
+func (s *Scope) dictItem(expr ast.Expr) (ast.Expr, ast.Expr) {
+	if st, ok := expr.(*ast.Subscript); ok {
+		if i, ok := st.Slice.(*ast.Index); ok && s.exprType(st.Value).ordered() {
+			return st.Value, i.Value
+		}
+	}
+
+	return nil, nil
+}
+
+// an int value, as a Go int (as indexes and counts are)
+func (s *Scope) goIntValue(val ast.Expr) *jen.Statement {
+	if n, ok := intConst(val); ok {
+		return jen.Lit(n)
+	}
+	switch t := s.exprType(val); {
+	case bigInts && t.Kind == KindInt:
+		return s.goExpr(val).Dot("Int").Call()
+
+	case !t.Known():
+		return jen.Qual(goRuntime, "Index").Call(s.goExpr(val))
+	}
+	return s.goExpr(val)
+}
+
+// an index in the sequence name, as a Go int (-x is len(name)-x)
+func (s *Scope) goIndex(name, val ast.Expr) *jen.Statement {
+	if unary, ok := val.(*ast.UnaryOp); ok && unary.Op == ast.USub { // -x
+		return jen.Len(s.goExpr(name)).Op("-").Add(s.goIntValue(unary.Operand))
+	}
+
+	return s.goIntValue(val)
+}
+
+func (s *Scope) goSlice(name ast.Expr, value ast.Slicer) *jen.Statement {
+	if item, key := s.dictItem(&ast.Subscript{Value: name, Slice: value}); item != nil {
+		return s.goExpr(item).Dot("Item").Call(s.goExpr(key))
+	}
+
+	if s.exprType(name).Kind == KindRange { // runtime.Range checks and adjusts the indexes
+		switch sl := value.(type) {
+		case *ast.Index:
+			item := s.goExpr(name).Dot("Item").Call(s.goIntValue(sl.Value))
+			if bigInts {
+				return jen.Qual(goRuntime, "NewInt").Call(item)
+			}
+			return item
+
+		case *ast.Slice:
+			if sl.Step == nil {
+				start, stop := jen.Lit(0), jen.Qual("math", "MaxInt")
+				if sl.Lower != nil {
+					start = s.goIntValue(sl.Lower)
+				}
+				if sl.Upper != nil {
+					stop = s.goIntValue(sl.Upper)
+				}
+				return s.goExpr(name).Dot("Slice").Call(start, stop)
+			}
+		}
+	}
+
+	stmt := s.goExpr(name)
+	start := jen.Empty()
+	end := jen.Empty()
+
+	switch sl := value.(type) {
+	case *ast.Slice:
+		if sl.Lower != nil {
+			start = s.goIndex(name, sl.Lower)
+		}
+		if sl.Upper != nil {
+			end = s.goIndex(name, sl.Upper)
+		}
+		if sl.Step != nil {
+			// if sl.Lower==nil && sl.Upper==nil && sl.Step == -1
+			// it would be a reverse slice, not that we can easily do it
+
+			log.Printf("at %v:%v", value.GetLineno(), value.GetColOffset())
+			panic("step index not implemented")
+		}
+		stmt.Add(jen.Index(start, end))
+
+	case *ast.Index:
+		stmt.Add(jen.Index(s.goIndex(name, sl.Value)))
+
+	case *ast.ExtSlice: // start:stop:step
+		log.Printf("at %v:%v", value.GetLineno(), value.GetColOffset())
+		panic("ExtSlice not implemented")
+	}
+
+	return stmt
+}
+
+func (s *Scope) goIdentifiers(l []ast.Identifier) *jen.Statement {
+	return jen.ListFunc(func(g *jen.Group) {
+		for _, i := range l {
+			g.Add(goId(i))
+		}
+	})
+}
+
+func (s *Scope) strIdentifiers(l []ast.Identifier) string {
+	var sx []string
+	for _, i := range l {
+		sx = append(sx, string(i))
+	}
+
+	return strings.Join(sx, ",")
+}
+
+func (s *Scope) goInitialized(otype *jen.Statement, values []ast.Expr) *jen.Statement {
+	return jen.Parens(otype.Clone().ValuesFunc(func(g *jen.Group) {
+		for _, v := range values {
+			g.Add(s.goExpr(v))
+		}
+	}))
+}
+
+func (s *Scope) goExprList(values []ast.Expr) *jen.Statement {
+	return jen.ListFunc(func(g *jen.Group) {
+		for _, v := range values {
+			g.Add(s.goExpr(v))
+		}
+	})
+}
+
+func (s *Scope) strExprList(values []ast.Expr) string {
+	var sx []string
+	for _, v := range values {
+		sx = append(sx, s.goExpr(v).GoString())
+	}
+
+	return strings.Join(sx, ",")
+}
+
+func (s *Scope) goExprOrList(expr ast.Expr) *jen.Statement {
+	if tuple, ok := expr.(*ast.Tuple); ok {
+		return s.goExpr(tuple.Elts)
+	}
+	return s.goExpr(expr)
+}
+
+func lenExpr(expr ast.Expr) int {
+	if tuple, ok := expr.(*ast.Tuple); ok {
+		return len(tuple.Elts)
+	}
+
+	return 1
+}
+
+func isNone(expr ast.Expr) bool {
+	if c, ok := expr.(*ast.NameConstant); ok {
+		return c.Value == py.None
+	}
+
+	return false
+}
+
+func isTuple(expr ast.Expr) bool {
+	_, ok := expr.(*ast.Tuple)
+	return ok
+}
+
+func isList(expr ast.Expr) bool {
+	_, ok := expr.(*ast.List)
+	return ok
+}
+
+// check for `__name__ == "__main__"`
+func isNameMain(expr ast.Expr) bool {
+	comp, ok := expr.(*ast.Compare)
+	if !ok {
+		return false
+	}
+	if len(comp.Ops) != 1 {
+		return false
+	}
+	if comp.Ops[0] != ast.Eq {
+		return false
+	}
+
+	name, ok := comp.Left.(*ast.Name)
+	if !ok {
+		return false
+	}
+	if string(name.Id) != "__name__" {
+		return false
+	}
+
+	str, ok := comp.Comparators[0].(*ast.Str)
+	if !ok {
+		return false
+	}
+	if string(str.S) != "__main__" {
+		return false
+	}
+
+	return true
+}
+
+// the names in expr, a name or a tuple of names (false if expr is a nested or starred pattern)
+func exprIds(expr ast.Expr) (ids []ast.Identifier, ok bool) {
+	if tuple, ok := expr.(*ast.Tuple); ok {
+		for _, x := range tuple.Elts {
+			name, ok := x.(*ast.Name)
+			if !ok {
+				return nil, false
+			}
+			ids = append(ids, name.Id)
+		}
+	} else {
+		name, ok := expr.(*ast.Name)
+		if !ok {
+			return nil, false
+		}
+		ids = append(ids, name.Id)
+	}
+
+	return ids, true
+}
+
+// add the names bound by the loop target, with the types of the elements of iter when they are known
+func (s *Scope) addLoopNames(target, iter ast.Expr) {
+	ids, ok := exprIds(target)
+	if !ok {
+		s.addPatternNames(target)
+		return
+	}
+
+	for i, t := range s.forTypes(len(ids), iter) {
+		s.addName(ids[i], t)
+	}
+}
+
+// the truth value of expr, as a Go boolean expression (for if, while, etc.)
+func (s *Scope) goTest(expr ast.Expr) *jen.Statement {
+	switch v := expr.(type) {
+	case *ast.BoolOp:
+		return s.goBoolTest(v)
+
+	case *ast.UnaryOp:
+		if v.Op == ast.Not {
+			return s.goNotTest(v.Operand)
+		}
+	}
+
+	if s.exprType(expr).Kind == KindNone {
+		return s.goNoneTest(expr, false)
+	}
+
+	return s.goTruth(s.goExpr(expr), s.exprType(expr))
+}
+
+// the constant truth value of expr, that is always None, still evaluating expr for its side effects
+func (s *Scope) goNoneTest(expr ast.Expr, result bool) *jen.Statement {
+	switch expr.(type) {
+	case *ast.Name, *ast.NameConstant:
+		return jen.Lit(result)
+
+	case *ast.Call: // a call without a return value can only be a statement
+		return jen.Func().Params().Bool().Block(s.goExpr(expr), jen.Return(jen.Lit(result))).Call()
+	}
+
+	return jen.Func().Params().Bool().Block(jen.Id("_").Op("=").Add(s.goExpr(expr)), jen.Return(jen.Lit(result))).Call()
+}
+
+// the negated truth value of expr
+func (s *Scope) goNotTest(expr ast.Expr) *jen.Statement {
+	switch v := expr.(type) {
+	case *ast.UnaryOp:
+		if v.Op == ast.Not {
+			return s.goTest(v.Operand)
+		}
+
+	case *ast.BoolOp, *ast.Compare:
+		return jen.Op("!").Parens(s.goTest(v))
+	}
+
+	value := s.goExpr(expr)
+
+	switch t := s.exprType(expr); t.Kind {
+	case KindBool:
+		return jen.Op("!").Add(value)
+
+	case KindInt, KindFloat, KindComplex:
+		return value.Op("==").Lit(0)
+
+	case KindStr, KindList, KindTuple, KindDict, KindSet:
+		if t.ordered() {
+			return value.Dot("Len").Call().Op("==").Lit(0)
+		}
+		return jen.Len(value).Op("==").Lit(0)
+
+	case KindNone:
+		return s.goNoneTest(expr, true)
+	}
+
+	return jen.Op("!").Add(s.goTruth(value, s.exprType(expr)))
+}
+
+// the truth value of a boolean operation (a and b, a or b) as a Go boolean expression
+func (s *Scope) goBoolTest(v *ast.BoolOp) *jen.Statement {
+	stmt := jen.Null()
+
+	for i, x := range v.Values {
+		if i > 0 {
+			stmt.Add(s.goBoolOp(v.Op))
+		}
+
+		// a and b binds tighter than a or b in Go, as in python
+		if b, ok := x.(*ast.BoolOp); ok && b.Op == ast.Or && v.Op == ast.And {
+			stmt.Parens(s.goBoolTest(b))
+		} else {
+			stmt.Add(s.goTest(x))
+		}
+	}
+
+	return stmt
+}
+
+// the truth value of value, of type t, following python rules:
+// empty strings and collections, zero, None are false
+func (s *Scope) goTruth(value *jen.Statement, t *Type) *jen.Statement {
+	switch t.Kind {
+	case KindBool:
+		return value
+
+	case KindInt, KindFloat, KindComplex:
+		if bigInts && t.Kind == KindInt {
+			return value.Dot("Bool").Call()
+		}
+		return value.Op("!=").Lit(0)
+
+	case KindStr, KindList, KindTuple, KindDict, KindSet:
+		if t.ordered() {
+			return value.Dot("Len").Call().Op(">").Lit(0)
+		}
+		return jen.Len(value).Op(">").Lit(0)
+
+	case KindNone:
+		return jen.False()
+
+	case KindObject:
+		switch {
+		case s.lookupFunc(t.Class+".__bool__") != nil:
+			return value.Dot("Bool").Call()
+
+		case s.lookupFunc(t.Class+".__len__") != nil:
+			return value.Dot("Len").Call().Op(">").Lit(0)
+		}
+
+		return value.Op("!=").Nil()
+	}
+
+	return jen.Qual(goRuntime, "Truthy").Call(value)
+}
+
+// a boolean operation used as a value: in python `a or b` returns a if it's true, b otherwise
+// (and `a and b` returns a if it's false, b otherwise)
+func (s *Scope) goBoolValue(v *ast.BoolOp) *jen.Statement {
+	t := s.exprType(v)
+	if t.Kind == KindBool {
+		return s.goBoolTest(v)
+	}
+
+	last := len(v.Values) - 1
+
+	return jen.Func().Params().Add(t.Go()).BlockFunc(func(g *jen.Group) {
+		for _, x := range v.Values[:last] {
+			test := s.goTruth(jen.Id("v"), s.exprType(x))
+			if v.Op == ast.And {
+				test = jen.Op("!").Parens(test)
+			}
+
+			g.If(jen.Id("v").Op(":=").Add(s.goExpr(x)), test).Block(jen.Return(jen.Id("v")))
+		}
+
+		g.Return(s.goExpr(v.Values[last]))
+	}).Call()
+}
+
+// a new dictionary from a dict literal: {k: v, ...} or {k: v, **other}
+func (s *Scope) goNewDict(keys, values []ast.Expr) *jen.Statement {
+	var stmt *jen.Statement
+	var kv []jen.Code
+
+	// the pairs up to the next **mapping
+	flush := func() {
+		if stmt == nil {
+			stmt = jen.Qual(goRuntime, "NewOrderedDict").Call(kv...)
+		} else if len(kv) > 0 {
+			stmt.Dot("Merge").Call(jen.Qual(goRuntime, "NewOrderedDict").Call(kv...))
+		}
+		kv = nil
+	}
+
+	for i, k := range keys {
+		if k == nil { // **mapping
+			if stmt == nil && len(kv) == 0 {
+				stmt = jen.Qual(goRuntime, "DictFrom").Call(s.goExpr(values[i]))
+				continue
+			}
+
+			flush()
+			stmt.Dot("Merge").Call(s.goExpr(values[i]))
+			continue
+		}
+
+		kv = append(kv, s.goExpr(k), s.goExpr(values[i]))
+	}
+
+	flush()
+	return stmt
+}
+
+// name refers to the python builtin, and not to a function, class or variable of the module
+func (s *Scope) isBuiltin(name string) bool {
+	return !s.isDefined(name) && s.lookupFunc(name) == nil && !s.classes[name]
+}
+
+// a child scope (as for comprehensions) where the variables names have type t
+func (s *Scope) scopeWith(t *Type, names ...string) *Scope {
+	cs := s.comprehensionScope()
+	for _, name := range names {
+		cs.addName(ast.Identifier(name), t)
+	}
+
+	return cs
+}
+
+// the call f(name)
+func callWith(f ast.Expr, name string) *ast.Call {
+	return &ast.Call{Func: f, Args: []ast.Expr{&ast.Name{Id: ast.Identifier(name)}}}
+}
+
+// iterate over the runtime.Range r (the elements are runtime.Int in -bigint mode)
+func goRangeAll(r *jen.Statement) *jen.Statement {
+	if bigInts {
+		return r.Dot("Ints").Call()
+	}
+
+	return r.Dot("All").Call()
+}
+
+// the type of the elements of the slice returned by goElements
+func (s *Scope) elementsType(iterable ast.Expr) *Type {
+	switch t := s.exprType(iterable); {
+	case t.Kind == KindList, t.Kind == KindTuple, t.Kind == KindSet, t.Kind == KindRange, t.Kind == KindStr, t.Kind == KindIter:
+		return t.elemType()
+
+	case t.Kind == KindDict && !t.ordered():
+		return t.Key
+	}
+
+	return typeAny
+}
+
+// the elements of iterable as a Go slice, and their type.
+// copied is false if the slice is the iterable itself (a list)
+func (s *Scope) goElements(iterable ast.Expr) (elems *jen.Statement, elem *Type, copied bool) {
+	t := s.exprType(iterable)
+
+	switch {
+	case t.Kind == KindList || t.Kind == KindTuple:
+		return s.goExpr(iterable), t.elemType(), false
+
+	case t.Kind == KindSet:
+		return s.goExpr(iterable).Dot("Items").Call(), t.elemType(), true
+
+	case t.Kind == KindRange:
+		return jen.Qual("slices", "Collect").Call(goRangeAll(s.goExpr(iterable))), t.elemType(), true
+
+	case t.Kind == KindStr:
+		return jen.Qual("slices", "Collect").Call(jen.Qual(goRuntime, "Chars").Call(s.goExpr(iterable))), typeStr, true
+
+	case t.Kind == KindIter:
+		return jen.Qual("slices", "Collect").Call(s.goIterSeq(iterable)), t.elemType(), true
+
+	case t.ordered():
+		return s.goExpr(iterable).Dot("Keys").Call(), typeAny, true
+
+	case t.Kind == KindDict:
+		return jen.Qual("slices", "Collect").Call(jen.Qual("maps", "Keys").Call(s.goExpr(iterable))), t.Key, true
+	}
+
+	return jen.Qual(goRuntime, "ListFrom").Call(s.goExpr(iterable)), typeAny, true
+}
+
+// the call returns a Go int, that is converted to a runtime.Int in -bigint mode
+// (the builtins not translated by goBuiltinCall, the str methods, list.index and list.count)
+func (s *Scope) goIntCall(call *ast.Call) bool {
+	if s.callSignature(call) != nil || s.exprType(call).Kind != KindInt {
+		return false
+	}
+
+	switch f := call.Func.(type) {
+	case *ast.Name:
+		return builtinTypes[string(f.Id)] != nil
+
+	case *ast.Attribute:
+		switch s.exprType(f.Value).Kind {
+		case KindStr:
+			return true
+
+		case KindList:
+			return f.Attr == "index" || f.Attr == "count"
+		}
+	}
+
+	return false
+}
+
+// the builtin functions (nil if call is not one of them, or the arguments are not supported).
+// In -bigint mode the Go ints they return are converted to runtime.Int
+func (s *Scope) goBuiltinCall(call *ast.Call) *jen.Statement {
+	f, ok := call.Func.(*ast.Name)
+	if !ok || !s.isBuiltin(string(f.Id)) || call.Starargs != nil || call.Kwargs != nil {
+		return nil
+	}
+
+	name := string(f.Id)
+	nargs := len(call.Args)
+
+	arg := func(i int) *jen.Statement {
+		return s.goExpr(call.Args[i])
+	}
+
+	keywords := map[string]ast.Expr{}
+	for _, k := range call.Keywords {
+		keywords[string(k.Arg)] = k.Value
+	}
+
+	// only the keywords in names are accepted
+	accepts := func(names ...string) bool {
+		for k := range keywords {
+			if !slices.Contains(names, k) {
+				return false
+			}
+		}
+		return true
+	}
+
+	goInt := func(stmt *jen.Statement) *jen.Statement {
+		if bigInts {
+			return jen.Qual(goRuntime, "NewInt").Call(stmt)
+		}
+		return stmt
+	}
+
+	// the type of the first argument
+	t := typeAny
+	if nargs > 0 {
+		t = s.exprType(call.Args[0])
+	}
+
+	switch name {
+	case "int":
+		if nargs > 2 || !accepts("base") {
+			break
+		}
+
+		if nargs == 0 {
+			return goInt(jen.Lit(0))
+		}
+
+		base := keywords["base"]
+		if nargs == 2 {
+			base = call.Args[1]
+		}
+
+		if base == nil && t.Kind == KindInt {
+			return arg(0)
+		}
+
+		// floats too: IntFrom raises on NaN and infinities, and on values that don't fit in an int
+		stmt := jen.Qual(goRuntime, "IntFrom").Call(arg(0))
+		if base != nil {
+			stmt = jen.Qual(goRuntime, "IntFrom").Call(arg(0), s.goIntValue(base))
+		}
+
+		if !bigInts {
+			return stmt.Dot("Int").Call()
+		}
+		return stmt
+
+	case "float":
+		if nargs > 1 || !accepts() {
+			break
+		}
+
+		if nargs == 0 {
+			return jen.Lit(0.0)
+		}
+
+		switch t.Kind {
+		case KindFloat:
+			return arg(0)
+
+		case KindInt:
+			if n, ok := intConst(call.Args[0]); ok {
+				return jen.Lit(float64(n))
+			}
+			if bigInts {
+				return arg(0).Dot("Float64").Call()
+			}
+			return jen.Float64().Call(arg(0))
+		}
+
+		return jen.Qual(goRuntime, "Float").Call(arg(0))
+
+	case "str":
+		if nargs > 1 || !accepts() {
+			break
+		}
+
+		if nargs == 0 {
+			return jen.Lit("")
+		}
+
+		switch t.Kind {
+		case KindStr:
+			return arg(0)
+
+		case KindInt:
+			if bigInts {
+				return arg(0).Dot("String").Call()
+			}
+			return jen.Qual("strconv", "Itoa").Call(arg(0))
+
+		case KindFloat:
+			return jen.Qual(goRuntime, "FormatFloat").Call(arg(0))
+		}
+
+		return jen.Qual(goRuntime, "Str").Call(arg(0))
+
+	case "repr":
+		if nargs == 1 && accepts() {
+			return jen.Qual(goRuntime, "Repr").Call(arg(0))
+		}
+
+	case "bool":
+		if nargs > 1 || !accepts() {
+			break
+		}
+
+		if nargs == 0 {
+			return jen.False()
+		}
+
+		return s.goTest(call.Args[0])
+
+	case "range":
+		if nargs == 0 || nargs > 3 || !accepts() {
+			break
+		}
+
+		if step, ok := intConst(call.Args[nargs-1]); ok && nargs == 3 && step == 0 {
+			errorf(call, "range() arg 3 must not be zero")
+		}
+
+		args := make([]jen.Code, nargs)
+		for i, a := range call.Args {
+			args[i] = s.goIntValue(a)
+		}
+
+		return jen.Qual(goRuntime, "NewRange").Call(args...)
+
+	case "list":
+		if nargs > 1 || !accepts() {
+			break
+		}
+
+		if nargs == 0 {
+			return goList.Clone().Values()
+		}
+
+		elems, _, copied := s.goElements(call.Args[0])
+		if !copied {
+			return jen.Qual("slices", "Clone").Call(elems)
+		}
+		return elems
+
+	case "len":
+		if nargs != 1 || !accepts() {
+			break
+		}
+
+		switch {
+		case t.ordered(), t.Kind == KindRange:
+			return goInt(arg(0).Dot("Len").Call())
+
+		case t.Kind == KindStr, t.Kind == KindList, t.Kind == KindTuple, t.Kind == KindDict, t.Kind == KindSet:
+			return goInt(jen.Len(arg(0)))
+		}
+
+		return goInt(jen.Qual(goRuntime, "Len").Call(arg(0)))
+
+	case "sorted":
+		if nargs != 1 || !accepts("key", "reverse") {
+			break
+		}
+
+		return s.goSorted(call.Args[0], keywords["key"], keywords["reverse"])
+
+	case "min", "max":
+		if nargs == 0 || !accepts("key", "default") || nargs > 1 && keywords["default"] != nil {
+			break
+		}
+
+		return s.goMinMax(name, call.Args, keywords["key"], keywords["default"])
+
+	case "sum":
+		if nargs == 0 || nargs > 2 || !accepts("start") {
+			break
+		}
+
+		start := keywords["start"]
+		if nargs == 2 {
+			start = call.Args[1]
+		}
+
+		return s.goSum(call.Args[0], start)
+
+	case "abs":
+		if nargs != 1 || !accepts() {
+			break
+		}
+
+		switch t.Kind {
+		case KindInt:
+			if bigInts {
+				return arg(0).Dot("Abs").Call()
+			}
+			return jen.Qual(goRuntime, "AbsInt").Call(arg(0))
+
+		case KindFloat:
+			return jen.Qual("math", "Abs").Call(arg(0))
+
+		case KindComplex:
+			return jen.Qual("math/cmplx", "Abs").Call(arg(0))
+		}
+
+		return jen.Qual(goRuntime, "Abs").Call(arg(0))
+
+	case "round":
+		if nargs == 0 || nargs > 2 || !accepts() {
+			break
+		}
+
+		switch {
+		case t.Kind == KindFloat && nargs == 1:
+			return goInt(jen.Int().Call(jen.Qual("math", "RoundToEven").Call(arg(0))))
+
+		case t.Kind == KindFloat:
+			return jen.Qual(goRuntime, "RoundFloat").Call(arg(0), s.goIntValue(call.Args[1]))
+
+		case t.Kind == KindInt && nargs == 1:
+			return arg(0)
+		}
+
+		if nargs == 2 {
+			return jen.Qual(goRuntime, "Round").Call(arg(0), s.goIntValue(call.Args[1]))
+		}
+		return jen.Qual(goRuntime, "Round").Call(arg(0))
+
+	case "divmod":
+		if nargs != 2 || !accepts() {
+			break
+		}
+
+		if s.exprType(call.Args[1]).Numeric() && t.Numeric() && isSimple(call.Args[0]) && isSimple(call.Args[1]) {
+			return goTuple.Clone().Values(
+				s.goBinOp(call.Args[0], ast.FloorDiv, call.Args[1]),
+				s.goBinOp(call.Args[0], ast.Modulo, call.Args[1]))
+		}
+
+		return jen.Qual(goRuntime, "DivMod").Call(arg(0), arg(1))
+
+	case "any", "all":
+		if nargs != 1 || !accepts() {
+			break
+		}
+
+		if g, ok := call.Args[0].(*ast.GeneratorExp); ok {
+			return s.goAnyAll(name, g)
+		}
+
+		elems, elem, _ := s.goElements(call.Args[0])
+
+		switch {
+		case elem.Kind == KindBool && name == "any":
+			return jen.Qual("slices", "Contains").Call(elems, jen.True())
+
+		case elem.Kind == KindBool:
+			return jen.Op("!").Qual("slices", "Contains").Call(elems, jen.False())
+
+		case elem.Known():
+			// any: some element is true, all: no element is false
+			cs := s.scopeWith(elem, "v")
+			var test ast.Expr = &ast.Name{Id: "v"}
+			if name == "all" {
+				test = &ast.UnaryOp{Op: ast.Not, Operand: test}
+			}
+
+			contains := jen.Qual("slices", "ContainsFunc").Call(elems,
+				jen.Func().Params(jen.Id("v").Add(elem.Go())).Bool().Block(jen.Return(cs.goTest(test))))
+			if name == "all" {
+				return jen.Op("!").Add(contains)
+			}
+			return contains
+		}
+
+		if name == "any" {
+			return jen.Qual(goRuntime, "AnyOf").Call(arg(0))
+		}
+		return jen.Qual(goRuntime, "AllOf").Call(arg(0))
+
+	case "reversed":
+		if nargs != 1 || !accepts() {
+			break
+		}
+
+		if t.Kind == KindList && t.Elem.Known() {
+			return jen.Func().Params().Add(t.Go()).Block(
+				jen.Id("r").Op(":=").Qual("slices", "Clone").Call(arg(0)),
+				jen.Qual("slices", "Reverse").Call(jen.Id("r")),
+				jen.Return(jen.Id("r"))).Call()
+		}
+
+		return jen.Qual(goRuntime, "Reversed").Call(arg(0))
+
+	case "zip":
+		if !accepts() {
+			break
+		}
+
+		args := make([]jen.Code, nargs)
+		for i := range args {
+			args[i] = arg(i)
+		}
+		return jen.Qual(goRuntime, "Zip").Call(args...)
+
+	case "enumerate":
+		if nargs == 0 || nargs > 2 || !accepts("start") {
+			break
+		}
+
+		start := jen.Lit(0)
+		if nargs == 2 {
+			start = s.goIntValue(call.Args[1])
+		} else if k := keywords["start"]; k != nil {
+			start = s.goIntValue(k)
+		}
+
+		return jen.Qual(goRuntime, "Enumerate").Call(arg(0), start)
+
+	case "map", "filter":
+		if nargs != 2 || !accepts() {
+			break
+		}
+
+		return s.goMapFilter(name, call.Args[0], call.Args[1])
+
+	case "iter":
+		if nargs == 1 && accepts() {
+			return jen.Qual(goRuntime, "Iter").Call(arg(0))
+		}
+
+	case "next":
+		if nargs == 1 && accepts() {
+			return jen.Qual(goRuntime, "Next").Call(arg(0))
+		}
+
+		if nargs == 2 && accepts() {
+			return jen.Qual(goRuntime, "Next").Call(arg(0), arg(1))
+		}
+
+	case "chr":
+		if nargs != 1 || !accepts() {
+			break
+		}
+
+		if t.Kind == KindInt {
+			return jen.String().Call(jen.Rune().Call(s.goIntValue(call.Args[0])))
+		}
+		return jen.Qual(goRuntime, "Chr").Call(arg(0))
+
+	case "ord":
+		if nargs != 1 || !accepts() {
+			break
+		}
+
+		return goInt(jen.Qual(goRuntime, "Ord").Call(arg(0)))
+
+	case "hex", "oct", "bin":
+		if nargs != 1 || !accepts() {
+			break
+		}
+
+		if t.Kind == KindInt {
+			format := map[string]string{"hex": "%#x", "oct": "%O", "bin": "%#b"}[name]
+			if bigInts {
+				return jen.Qual("fmt", "Sprintf").Call(jen.Lit(format), arg(0).Dot("Big").Call())
+			}
+			return jen.Qual("fmt", "Sprintf").Call(jen.Lit(format), arg(0))
+		}
+
+		return jen.Qual(goRuntime, strings.ToUpper(name[:1])+name[1:]).Call(arg(0))
+
+	case "hash":
+		if nargs != 1 || !accepts() {
+			break
+		}
+
+		if t.Kind == KindInt && !bigInts { // small ints are their own hash
+			return arg(0)
+		}
+		return goInt(jen.Qual(goRuntime, "Hash").Call(arg(0)))
+	}
+
+	return nil
+}
+
+// the type returned by the builtin functions translated by goBuiltinCall (nil if call is not one of them)
+func (s *Scope) builtinType(call *ast.Call) *Type {
+	f, ok := call.Func.(*ast.Name)
+	if !ok || !s.isBuiltin(string(f.Id)) || len(call.Args) == 0 {
+		return nil
+	}
+
+	t := s.exprType(call.Args[0])
+
+	switch string(f.Id) {
+	case "list":
+		return listOf(s.elementsType(call.Args[0]))
+
+	case "sorted":
+		return listOf(t.elemType())
+
+	case "reversed":
+		if t.Kind == KindList {
+			return t
+		}
+		return typeList
+
+	case "min", "max":
+		return s.minMaxType(call.Args, callKeyword(call, "key"), callKeyword(call, "default"))
+
+	case "sum":
+		start := callKeyword(call, "start")
+		if len(call.Args) == 2 {
+			start = call.Args[1]
+		}
+		return s.sumType(call.Args[0], start)
+
+	case "abs":
+		switch t.Kind {
+		case KindInt, KindFloat:
+			return t
+		case KindComplex:
+			return typeFloat
+		}
+		return typeAny
+
+	case "round":
+		switch {
+		case t.Kind == KindFloat && len(call.Args) == 1, t.Kind == KindInt && len(call.Args) == 1:
+			return typeInt
+		case t.Kind == KindFloat:
+			return typeFloat
+		}
+		return typeAny
+
+	case "divmod":
+		return typeTuple
+
+	case "zip", "enumerate":
+		return typeList
+
+	case "map":
+		if len(call.Args) == 2 {
+			elem := s.exprType(call.Args[1]).elemType()
+			return listOf(s.scopeWith(elem, "v").exprType(callWith(call.Args[0], "v")))
+		}
+
+	case "filter":
+		if len(call.Args) == 2 {
+			return listOf(s.exprType(call.Args[1]).elemType())
+		}
+	}
+
+	return nil
+}
+
+// the value of the keyword argument name (nil if not present)
+func callKeyword(call *ast.Call, name string) ast.Expr {
+	for _, k := range call.Keywords {
+		if string(k.Arg) == name {
+			return k.Value
+		}
+	}
+
+	return nil
+}
+
+// an expression that can be evaluated more than once (a name or a constant)
+func isSimple(expr ast.Expr) bool {
+	switch v := expr.(type) {
+	case *ast.Name, *ast.Num, *ast.Str, *ast.NameConstant:
+		return true
+
+	case *ast.UnaryOp:
+		return isSimple(v.Operand)
+	}
+
+	return false
+}
+
+// sorted(iterable, key=key, reverse=reverse) (key and reverse can be nil)
+func (s *Scope) goSorted(iterable, key, reverse ast.Expr) *jen.Statement {
+	t := s.exprType(iterable)
+	elems, elem, copied := s.goElements(iterable)
+
+	if key == nil && reverse == nil && elem.orderable() {
+		switch {
+		case t.Kind == KindList:
+			return jen.Qual("slices", "Sorted").Call(jen.Qual("slices", "Values").Call(s.goExpr(iterable)))
+
+		case t.Kind == KindSet || t.Kind == KindDict && !t.ordered():
+			return jen.Qual("slices", "Sorted").Call(jen.Qual("maps", "Keys").Call(s.goExpr(iterable)))
+		}
+	}
+
+	if key == nil && !elem.Known() {
+		if c, ok := reverse.(*ast.NameConstant); ok && c.Value == py.True {
+			return jen.Qual(goRuntime, "Sorted").Call(s.goExpr(iterable), jen.True())
+		} else if reverse == nil || ok && c.Value == py.False {
+			return jen.Qual(goRuntime, "Sorted").Call(s.goExpr(iterable), jen.False())
+		}
+	}
+
+	if !copied {
+		elems = jen.Qual("slices", "Clone").Call(elems)
+	}
+
+	lt := listOf(elem)
+	l := s.generatedName("_l")
+	return jen.Func().Params().Add(lt.Go()).Block(
+		jen.Id(l).Op(":=").Add(elems),
+		s.goSort(lt, jen.Id(l), key, reverse),
+		jen.Return(jen.Id(l))).Call()
+}
+
+// the type of min(args...) or max(args...)
+func (s *Scope) minMaxType(args []ast.Expr, key, def ast.Expr) *Type {
+	var t *Type
+
+	if len(args) == 1 {
+		t = s.exprType(args[0]).elemType()
+	} else {
+		t = s.exprType(args[0])
+		for _, a := range args[1:] {
+			at := s.exprType(a)
+			switch {
+			case t.Equal(at):
+			case key == nil && (t.Kind == KindInt || t.Kind == KindFloat) && (at.Kind == KindInt || at.Kind == KindFloat):
+				t = typeFloat
+			default:
+				return typeAny
+			}
+		}
+	}
+
+	if def != nil && !s.exprType(def).Equal(t) {
+		return typeAny
+	}
+
+	return t
+}
+
+// min(args..., key=key, default=def) or max(args..., key=key, default=def) (key and def can be nil)
+func (s *Scope) goMinMax(name string, args []ast.Expr, key, def ast.Expr) *jen.Statement {
+	t := s.minMaxType(args, key, def)
+
+	var elems *jen.Statement
+	var elem *Type
+
+	if len(args) == 1 {
+		elems, elem, _ = s.goElements(args[0])
+	} else {
+		values := make([]jen.Code, len(args))
+		for i, a := range args {
+			values[i] = s.goExpr(a)
+			if t.Kind == KindFloat && s.exprType(a).Kind == KindInt {
+				if n, ok := intConst(a); ok {
+					values[i] = jen.Lit(float64(n))
+				} else if bigInts {
+					values[i] = s.goExpr(a).Dot("Float64").Call()
+				} else {
+					values[i] = jen.Float64().Call(values[i])
+				}
+			}
+		}
+
+		if key == nil && t.orderable() { // the Go builtins
+			return jen.Id(name).Call(values...)
+		}
+
+		elem = t
+		elems = listOf(t).Go().Values(values...)
+	}
+
+	switch {
+	case key == nil && def == nil && elem.orderable():
+		return jen.Qual("slices", strings.ToUpper(name[:1])+name[1:]).Call(elems)
+
+	case key == nil && !elem.Known():
+		fn := jen.Qual(goRuntime, strings.ToUpper(name[:1])+name[1:])
+		if len(args) > 1 {
+			return fn.Call(elems)
+		}
+		if def != nil {
+			return fn.Call(s.goExpr(args[0]), s.goExpr(def))
+		}
+		return fn.Call(s.goExpr(args[0]))
+	}
+
+	// a loop comparing the elements (or their keys).
+	// The names are generated, since the key function and the default can use any name
+	op := "<"
+	if name == "max" {
+		op = ">"
+	}
+
+	empty := jen.Panic(jen.Lit(fmt.Sprintf("ValueError: %v() arg is an empty sequence", name)))
+	if def != nil {
+		empty = jen.Return(s.goExpr(def))
+	}
+
+	l := s.generatedName("_l")
+	m, v, k, mk := l+"m", l+"v", l+"k", l+"mk"
+
+	loop := jen.For(jen.List(jen.Op("_"), jen.Id(v)).Op(":=").Range().Id(l).Index(jen.Lit(1), jen.Empty()))
+
+	if key == nil {
+		compare := jen.Qual(goRuntime, "Compare").Call(jen.Id(v), jen.Id(m))
+		if elem.orderable() {
+			compare = jen.Id(v).Op(op).Id(m)
+		} else {
+			compare.Op(op).Lit(0)
+		}
+
+		loop.Block(jen.If(compare).Block(jen.Id(m).Op("=").Id(v)))
+	} else {
+		// the key of each element is computed once
+		cs := s.scopeWith(elem, m, v)
+		mkey, kt := cs.goExpr(callWith(key, m)), cs.exprType(callWith(key, m))
+
+		compare := jen.Qual(goRuntime, "Compare").Call(jen.Id(k), jen.Id(mk)).Op(op).Lit(0)
+		if kt.orderable() {
+			compare = jen.Id(k).Op(op).Id(mk)
+		}
+
+		loop.Block(jen.If(jen.Id(k).Op(":=").Add(cs.goExpr(callWith(key, v))), compare).Block(
+			jen.List(jen.Id(m), jen.Id(mk)).Op("=").List(jen.Id(v), jen.Id(k))))
+		loop = jen.Id(mk).Op(":=").Add(mkey).Line().Add(loop)
+	}
+
+	return jen.Func().Params().Add(t.Go()).Block(
+		jen.Id(l).Op(":=").Add(elems),
+		jen.If(jen.Len(jen.Id(l)).Op("==").Lit(0)).Block(empty),
+		jen.Id(m).Op(":=").Id(l).Index(jen.Lit(0)),
+		loop,
+		jen.Return(jen.Id(m))).Call()
+}
+
+// the type of sum(iterable, start)
+func (s *Scope) sumType(iterable, start ast.Expr) *Type {
+	elem := s.exprType(iterable).elemType()
+	if elem.Kind != KindInt && elem.Kind != KindFloat {
+		return typeAny
+	}
+
+	if start == nil {
+		return elem
+	}
+
+	switch st := s.exprType(start); {
+	case st.Equal(elem):
+		return elem
+
+	case st.Kind == KindFloat && elem.Kind == KindInt && !bigInts:
+		return typeFloat
+	}
+
+	return typeAny
+}
+
+// sum(iterable, start) (start can be nil)
+func (s *Scope) goSum(iterable, start ast.Expr) *jen.Statement {
+	t := s.sumType(iterable, start)
+	if !t.Known() {
+		if start != nil {
+			return jen.Qual(goRuntime, "Sum").Call(s.goExpr(iterable), s.goExpr(start))
+		}
+		return jen.Qual(goRuntime, "Sum").Call(s.goExpr(iterable))
+	}
+
+	// the loops of a generator are inlined, without collecting the elements.
+	// The names of the result and of the element are generated, so that they can't shadow
+	// (or be shadowed by) the names used in start and in the generator
+	g, inline := iterable.(*ast.GeneratorExp)
+	sum := s.generatedName("_s")
+	value := sum + "v"
+
+	var elems *jen.Statement
+	var elem *Type
+
+	if inline {
+		elem = s.exprType(g).elemType()
+	} else {
+		elems, elem, _ = s.goElements(iterable)
+	}
+
+	v := jen.Id(value)
+	if t.Kind == KindFloat && elem.Kind == KindInt {
+		v = jen.Float64().Call(v)
+	}
+
+	add := jen.Id(sum).Op("+=").Add(v)
+	if bigInts && t.Kind == KindInt {
+		add = jen.Id(sum).Op("=").Id(sum).Dot("Add").Call(v)
+	}
+
+	init := jen.Null()
+	if start != nil {
+		init = jen.Id(sum).Op("=").Add(s.goExpr(start))
+	}
+
+	var loop *jen.Statement
+	if inline {
+		loop = s.goComprehension(g.Generators, []ast.Expr{g.Elt}, func(cs *Scope) jen.Code {
+			return jen.Id(value).Op(":=").Add(cs.goExpr(g.Elt)).Line().Add(add)
+		})
+	} else {
+		loop = jen.For(jen.List(jen.Op("_"), jen.Id(value)).Op(":=").Range().Add(elems)).Block(add)
+	}
+
+	return jen.Func().Params().Params(jen.Id(sum).Add(t.Go())).Block(
+		init,
+		loop,
+		jen.Return()).Call()
+}
+
+// any(elt for ...) or all(elt for ...): the loops of the generator,
+// stopping at the first true (any) or false (all) element
+func (s *Scope) goAnyAll(name string, g *ast.GeneratorExp) *jen.Statement {
+	found, test := true, g.Elt
+	if name == "all" {
+		found, test = false, &ast.UnaryOp{Op: ast.Not, Operand: g.Elt}
+	}
+
+	return jen.Func().Params().Bool().Block(
+		s.goComprehension(g.Generators, []ast.Expr{g.Elt}, func(cs *Scope) jen.Code {
+			return jen.If(cs.goTest(test)).Block(jen.Return(jen.Lit(found)))
+		}),
+		jen.Return(jen.Lit(!found))).Call()
+}
+
+// map(f, iterable) or filter(f, iterable), as lists.
+// The names are generated, since f can be a lambda (inlined) using any name
+func (s *Scope) goMapFilter(name string, f, iterable ast.Expr) *jen.Statement {
+	elems, elem, _ := s.goElements(iterable)
+
+	r := s.generatedName("_r")
+	l, v := r+"l", r+"v"
+	cs := s.scopeWith(elem, v)
+
+	if name == "map" {
+		call := callWith(f, v)
+		rt := listOf(cs.exprType(call))
+
+		return jen.Func().Params().Add(rt.Go()).Block(
+			jen.Id(l).Op(":=").Add(elems),
+			jen.Id(r).Op(":=").Make(rt.Go(), jen.Lit(0), jen.Len(jen.Id(l))),
+			jen.For(jen.List(jen.Op("_"), jen.Id(v)).Op(":=").Range().Id(l)).Block(
+				jen.Id(r).Op("=").Append(jen.Id(r), cs.goExpr(call))),
+			jen.Return(jen.Id(r))).Call()
+	}
+
+	// filter(None, iterable) keeps the true elements
+	var test ast.Expr = callWith(f, v)
+	if c, ok := f.(*ast.NameConstant); ok && c.Value == py.None {
+		test = &ast.Name{Id: ast.Identifier(v)}
+	}
+
+	rt := listOf(elem)
+	return jen.Func().Params().Params(jen.Id(r).Add(rt.Go())).Block(
+		jen.For(jen.List(jen.Op("_"), jen.Id(v)).Op(":=").Range().Add(elems)).Block(
+			jen.If(cs.goTest(test)).Block(
+				jen.Id(r).Op("=").Append(jen.Id(r), jen.Id(v)))),
+		jen.Return()).Call()
+}
+
+// the list methods (nil if call is not one of them).
+// The methods that change the length of the list assign the new slice to the receiver,
+// and when a single expression isn't enough the method is translated to a function literal called in place.
+func (s *Scope) goListCall(call *ast.Call) *jen.Statement {
+	attr, ok := call.Func.(*ast.Attribute)
+	if !ok {
+		return nil
+	}
+
+	t := s.exprType(attr.Value)
+	if t.Kind != KindList {
+		return nil
+	}
+
+	l := s.goExpr(attr.Value)
+	typed := t.Elem.Known()
+	nargs := len(call.Args)
+
+	arg := func(i int) *jen.Statement {
+		return s.goExpr(call.Args[i])
+	}
+
+	// the position of the first element equal to v, or -1
+	find := func(v *jen.Statement) *jen.Statement {
+		if t.Elem.comparable() {
+			return jen.Qual("slices", "Index").Call(l.Clone(), v)
+		}
+
+		e := s.generatedName("_e")
+		return jen.Qual("slices", "IndexFunc").Call(l.Clone(), jen.Func().Params(jen.Id(e).Add(t.Elem.Go())).Bool().Block(
+			jen.Return(jen.Qual(goRuntime, "Eq").Call(jen.Id(e), v))))
+	}
+
+	// the names of the variables of the function literals are generated,
+	// since the receiver and the arguments can use any name
+
+	switch string(attr.Attr) {
+	case "extend":
+		if nargs == 1 && len(call.Keywords) == 0 {
+			return s.goExtend(attr.Value, call.Args[0])
+		}
+
+	case "insert":
+		if nargs == 2 {
+			return l.Clone().Op("=").Qual(goRuntime, "ListInsert").Call(l, s.goIntValue(call.Args[0]), arg(1))
+		}
+
+	case "pop":
+		if !typed && nargs <= 1 {
+			args := []jen.Code{jen.Op("&").Add(l)}
+			if nargs == 1 {
+				args = append(args, s.goIndex(attr.Value, call.Args[0]))
+			}
+			return jen.Qual(goRuntime, "ListPop").Call(args...)
+		}
+
+		if nargs <= 1 {
+			i := jen.Len(l.Clone()).Op("-").Lit(1)
+			if nargs == 1 {
+				i = s.goIndex(attr.Value, call.Args[0])
+			}
+
+			pi := s.generatedName("_i")
+			pv := pi + "v"
+			return jen.Func().Params().Add(t.Elem.Go()).Block(
+				jen.Id(pi).Op(":=").Add(i),
+				jen.Id(pv).Op(":=").Add(l.Clone()).Index(jen.Id(pi)),
+				l.Clone().Op("=").Qual("slices", "Delete").Call(l.Clone(), jen.Id(pi), jen.Id(pi).Op("+").Lit(1)),
+				jen.Return(jen.Id(pv))).Call()
+		}
+
+	case "remove":
+		if !typed && nargs == 1 {
+			return l.Clone().Op("=").Qual(goRuntime, "ListRemove").Call(l, arg(0))
+		}
+
+		if nargs == 1 {
+			pi := s.generatedName("_i")
+			return jen.Func().Params().Block(
+				jen.Id(pi).Op(":=").Add(find(arg(0))),
+				jen.If(jen.Id(pi).Op("<").Lit(0)).Block(
+					jen.Panic(jen.Lit("ValueError: list.remove(x): x not in list"))),
+				l.Clone().Op("=").Qual("slices", "Delete").Call(l.Clone(), jen.Id(pi), jen.Id(pi).Op("+").Lit(1))).Call()
+		}
+
+	case "index":
+		if !typed && nargs == 1 {
+			return jen.Qual(goRuntime, "ListIndex").Call(l, arg(0))
+		}
+
+		if nargs == 1 {
+			pi := s.generatedName("_i")
+			return jen.Func().Params().Int().Block(
+				jen.If(jen.Id(pi).Op(":=").Add(find(arg(0))), jen.Id(pi).Op(">=").Lit(0)).Block(
+					jen.Return(jen.Id(pi))),
+				jen.Panic(jen.Lit("ValueError: list.index(x): x not in list"))).Call()
+		}
+
+	case "count":
+		if !typed && nargs == 1 {
+			return jen.Qual(goRuntime, "ListCount").Call(l, arg(0))
+		}
+
+		if nargs == 1 {
+			pn := s.generatedName("_n")
+			pe := pn + "e"
+			eq := jen.Id(pe).Op("==").Add(arg(0))
+			if !t.Elem.comparable() {
+				eq = jen.Qual(goRuntime, "Eq").Call(jen.Id(pe), arg(0))
+			}
+
+			return jen.Func().Params().Params(jen.Id(pn).Int()).Block(
+				jen.For(jen.List(jen.Op("_"), jen.Id(pe)).Op(":=").Range().Add(l)).Block(
+					jen.If(eq).Block(jen.Id(pn).Op("++"))),
+				jen.Return()).Call()
+		}
+
+	case "clear":
+		if nargs == 0 {
+			return l.Clone().Op("=").Add(l).Index(jen.Empty(), jen.Lit(0))
+		}
+
+	case "copy":
+		if nargs == 0 {
+			return jen.Qual("slices", "Clone").Call(l)
+		}
+
+	case "reverse":
+		if typed && nargs == 0 {
+			return jen.Qual("slices", "Reverse").Call(l)
+		}
+
+	case "sort":
+		if nargs != 0 {
+			break
+		}
+
+		var key, reverse ast.Expr
+		for _, k := range call.Keywords {
+			switch string(k.Arg) {
+			case "key":
+				key = k.Value
+			case "reverse":
+				reverse = k.Value
+			default:
+				return nil
+			}
+		}
+
+		return s.goSort(t, l, key, reverse)
+	}
+
+	return nil
+}
+
+// sort in place the slice l, of type t, as list.sort(key=key, reverse=reverse) (key and reverse can be nil)
+func (s *Scope) goSort(t *Type, l *jen.Statement, key, reverse ast.Expr) *jen.Statement {
+	elem := t.elemType()
+
+	var rev *bool // nil if the value is not known at translation time
+	switch c := reverse.(type) {
+	case nil:
+		rev = new(bool)
+	case *ast.NameConstant:
+		if c.Value == py.True || c.Value == py.False {
+			rev = new(bool)
+			*rev = c.Value == py.True
+		}
+	}
+
+	if key == nil && rev != nil {
+		switch {
+		case !t.Elem.Known():
+			return jen.Qual(goRuntime, "ListSort").Call(l, jen.Lit(*rev))
+
+		case elem.orderable() && !*rev:
+			return jen.Qual("slices", "Sort").Call(l)
+		}
+	}
+
+	// the comparison function compares a and b, or key(a) and key(b).
+	// The names are generated, since the key function and reverse can use any name
+	pa := s.generatedName("_a")
+	pb := pa + "b"
+	a, b := jen.Id(pa), jen.Id(pb)
+	compare := jen.Qual(goRuntime, "Compare")
+	if elem.orderable() {
+		compare = jen.Qual("cmp", "Compare")
+	}
+
+	sort := jen.Qual("slices", "SortFunc")
+	if key != nil {
+		cs := s.scopeWith(elem, pa, pb)
+		ka, kb := callWith(key, pa), callWith(key, pb)
+		a, b = cs.goExpr(ka), cs.goExpr(kb)
+
+		compare = jen.Qual(goRuntime, "Compare")
+		if cs.exprType(ka).orderable() {
+			compare = jen.Qual("cmp", "Compare")
+		}
+
+		sort = jen.Qual("slices", "SortStableFunc") // python sort is stable
+	}
+
+	var body []jen.Code
+	switch {
+	case rev == nil:
+		body = append(body, jen.If(s.goTest(reverse)).Block(
+			jen.List(jen.Id(pa), jen.Id(pb)).Op("=").List(jen.Id(pb), jen.Id(pa))))
+	case *rev:
+		a, b = b, a
+	}
+
+	body = append(body, jen.Return(compare.Call(a, b)))
+	return sort.Call(l, jen.Func().Params(jen.List(jen.Id(pa), jen.Id(pb)).Add(elem.Go())).Int().Block(body...))
+}
+
+// del l[i], del l[i:j] or del l[i:j:k] on a list
+func (s *Scope) goDeleteItems(l ast.Expr, index ast.Slicer) *jen.Statement {
+	var start, end *jen.Statement
+
+	switch sl := index.(type) {
+	case *ast.Index:
+		if n, ok := intConst(sl.Value); ok && n >= 0 {
+			start, end = jen.Lit(n), jen.Lit(n+1)
+		} else if ok && n == -1 {
+			start, end = jen.Len(s.goExpr(l)).Op("-").Lit(1), jen.Len(s.goExpr(l))
+		} else {
+			start = s.goIndex(l, sl.Value)
+			end = start.Clone().Op("+").Lit(1)
+		}
+
+	case *ast.Slice:
+		if sl.Step != nil { // the runtime adjusts the bounds, that depend on the sign of step
+			bound := func(v ast.Expr) *jen.Statement {
+				if v == nil {
+					return jen.Nil()
+				}
+				return s.goIntValue(v)
+			}
+
+			return s.goExpr(l).Op("=").Qual(goRuntime, "ListDeleteSlice").Call(s.goExpr(l), bound(sl.Lower), bound(sl.Upper), s.goIntValue(sl.Step))
+		}
+
+		start, end = jen.Lit(0), jen.Len(s.goExpr(l))
+		if sl.Lower != nil {
+			start = s.goIndex(l, sl.Lower)
+		}
+		if sl.Upper != nil {
+			end = s.goIndex(l, sl.Upper)
+		}
+
+	default:
+		log.Printf("at %v:%v", index.GetLineno(), index.GetColOffset())
+		panic("ExtSlice not implemented")
+	}
+
+	return s.goExpr(l).Op("=").Qual("slices", "Delete").Call(s.goExpr(l), start, end)
+}
+
+// l.extend(iterable) or l += iterable: append the elements of iterable to the list l
+func (s *Scope) goExtend(l, iterable ast.Expr) *jen.Statement {
+	lt, it := s.exprType(l), s.exprType(iterable)
+	values := s.goExpr(iterable)
+
+	switch {
+	case lt.Equal(it) || lt.Elem.Known() && (it.Kind == KindList || it.Kind == KindTuple) && lt.Elem.Equal(it.Elem):
+		// same type, the values can be appended as they are
+
+	case lt.Elem.Known() && it.Kind == KindSet && lt.Elem.Equal(it.Elem):
+		values.Dot("Items").Call()
+
+	case !lt.Elem.Known():
+		values = jen.Qual(goRuntime, "ListFrom").Call(values)
+
+	case it.Kind == KindRange && lt.Elem.Equal(it.elemType()):
+		values = jen.Qual("slices", "Collect").Call(goRangeAll(values))
+
+	default:
+		// a typed list extended with values of a different type
+		v := jen.Id("v")
+		switch {
+		case it.Kind == KindStr: // the elements are runes
+			v = jen.String().Call(v)
+		case !it.elemType().Known():
+			v.Assert(lt.Elem.Go())
+		}
+
+		return jen.For(jen.List(jen.Op("_"), jen.Id("v")).Op(":=").Range().Add(values)).Block(
+			s.goExpr(l).Op("=").Append(s.goExpr(l), v))
+	}
+
+	return s.goExpr(l).Op("=").Append(s.goExpr(l), values.Op("..."))
+}
+
+// the python dict methods (and the runtime.OrderedDict methods implementing them)
+var dictMethods = map[string]string{
+	"get":        "Get",
+	"setdefault": "SetDefault",
+	"pop":        "Pop",
+	"popitem":    "PopItem",
+	"update":     "Update",
+	"keys":       "Keys",
+	"values":     "Values",
+	"items":      "Items",
+	"copy":       "Copy",
+	"clear":      "Clear",
+}
+
+// the types returned by dict methods
+var dictMethodTypes = map[string]*Type{
+	"popitem": typeTuple,
+	"keys":    typeList,
+	"values":  typeList,
+	"items":   typeList,
+	"copy":    typeDict,
+}
+
+// dict() and the methods of dictionaries translated to runtime.OrderedDict (nil if call is not one of them)
+func (s *Scope) goDictCall(call *ast.Call) *jen.Statement {
+	// keyword arguments, as a dictionary
+	keywords := func() *jen.Statement {
+		keys := make([]ast.Expr, len(call.Keywords))
+		values := make([]ast.Expr, len(call.Keywords))
+		for i, k := range call.Keywords {
+			keys[i] = &ast.Str{S: py.String(k.Arg)}
+			values[i] = k.Value
+		}
+		return s.goNewDict(keys, values)
+	}
+
+	switch ff := call.Func.(type) {
+	case *ast.Name:
+		if string(ff.Id) != "dict" || s.isDefined("dict") || len(call.Args) > 1 || call.Starargs != nil || call.Kwargs != nil {
+			return nil
+		}
+
+		if len(call.Args) == 0 {
+			return keywords()
+		}
+
+		stmt := jen.Qual(goRuntime, "DictFrom").Call(s.goExpr(call.Args[0]))
+		if len(call.Keywords) > 0 {
+			stmt.Dot("Merge").Call(keywords())
+		}
+		return stmt
+
+	case *ast.Attribute:
+		m, ok := dictMethods[string(ff.Attr)]
+		if !ok || !s.exprType(ff.Value).ordered() {
+			return nil
+		}
+
+		args := make([]jen.Code, len(call.Args))
+		for i, a := range call.Args {
+			args[i] = s.goExpr(a)
+		}
+
+		if m == "Update" && len(call.Keywords) > 0 { // d.update(k=v)
+			args = append(args, keywords())
+		}
+
+		return s.goExpr(ff.Value).Dot(m).Call(args...)
+	}
+
+	return nil
+}
+
+// the dict methods on Go maps (runtime.Dict or map[K]V), nil if call is not one of them.
+// When a single expression isn't enough, the method is translated to a call of a runtime helper.
+func (s *Scope) goMapCall(call *ast.Call) *jen.Statement {
+	attr, ok := call.Func.(*ast.Attribute)
+	if !ok {
+		return nil
+	}
+
+	t := s.exprType(attr.Value)
+	if t.Kind != KindDict || t.ordered() {
+		return nil
+	}
+
+	m := s.goExpr(attr.Value)
+	nargs := len(call.Args)
+
+	arg := func(i int) *jen.Statement {
+		return s.goExpr(call.Args[i])
+	}
+
+	switch string(attr.Attr) {
+	case "get":
+		switch nargs {
+		case 1: // missing keys return None
+			if t.Elem.Known() {
+				return jen.Qual(goRuntime, "MapLookup").Call(m, arg(0))
+			}
+			return m.Index(arg(0)) // runtime.Dict: the zero value is nil
+
+		case 2:
+			return jen.Qual(goRuntime, "MapGet").Call(m, arg(0), arg(1))
+		}
+
+	case "setdefault":
+		if nargs == 2 {
+			return jen.Qual(goRuntime, "MapSetDefault").Call(m, arg(0), arg(1))
+		}
+
+	case "pop":
+		if nargs == 1 || nargs == 2 {
+			return jen.Qual(goRuntime, "MapPop").Call(m, s.goExprList(call.Args))
+		}
+
+	case "popitem": // Go maps are not ordered, so this is an arbitrary item
+		if nargs == 0 {
+			return jen.Qual(goRuntime, "MapPopItem").Call(m)
+		}
+
+	case "update":
+		if nargs == 1 && len(call.Keywords) == 0 {
+			at := s.exprType(call.Args[0])
+			if at.Equal(t) {
+				return jen.Qual("maps", "Copy").Call(m, arg(0))
+			}
+
+			// the items need to be converted to the map types
+			items := jen.Qual("maps", "All").Call(arg(0))
+			if at.ordered() {
+				items = arg(0).Dot("All").Call()
+			}
+			return jen.Qual(goRuntime, "MapUpdate").Call(m, items)
+		}
+
+		if nargs == 0 && len(call.Keywords) > 0 {
+			if t.Key.Kind != KindStr {
+				errorf(call, "update() with keyword arguments needs a dict with str keys, not %v", t.Key.Go().GoString())
+				break
+			}
+
+			return jen.Qual("maps", "Copy").Call(m, t.Go().Values(jen.DictFunc(func(d jen.Dict) {
+				for _, k := range call.Keywords {
+					d[jen.Lit(string(k.Arg))] = s.goExpr(k.Value)
+				}
+			})))
+		}
+
+	case "keys":
+		if nargs == 0 {
+			return jen.Qual("slices", "Collect").Call(jen.Qual("maps", "Keys").Call(m))
+		}
+
+	case "values":
+		if nargs == 0 {
+			return jen.Qual("slices", "Collect").Call(jen.Qual("maps", "Values").Call(m))
+		}
+
+	case "items":
+		if nargs == 0 {
+			return jen.Func().Params().Params(jen.Id("items").Add(goList)).Block(
+				jen.For(jen.List(jen.Id("k"), jen.Id("v")).Op(":=").Range().Add(m)).Block(
+					jen.Id("items").Op("=").Append(jen.Id("items"), goTuple.Clone().Values(jen.Id("k"), jen.Id("v")))),
+				jen.Return()).Call()
+		}
+
+	case "copy":
+		if nargs == 0 {
+			return jen.Qual("maps", "Clone").Call(m)
+		}
+
+	case "clear":
+		if nargs == 0 {
+			return jen.Id("clear").Call(m)
+		}
+	}
+
+	return nil
+}
+
+// set(), frozenset() and the set methods (nil if call is not one of them)
+func (s *Scope) goSetCall(call *ast.Call) *jen.Statement {
+	if len(call.Keywords) > 0 {
+		return nil
+	}
+
+	switch ff := call.Func.(type) {
+	case *ast.Name:
+		if name := string(ff.Id); (name == "set" || name == "frozenset") && !s.isDefined(name) && len(call.Args) <= 1 {
+			t := s.exprType(call)
+			if len(call.Args) == 0 {
+				return s.goNewSet(t)
+			}
+			return s.goSetFrom(t, call.Args[0])
+		}
+
+	case *ast.Attribute:
+		t := s.exprType(ff.Value)
+		if t.Kind != KindSet {
+			return nil
+		}
+
+		name := string(ff.Attr)
+		recv := s.goExpr(ff.Value)
+
+		args := make([]jen.Code, len(call.Args))
+		for i, a := range call.Args {
+			if name == "add" || name == "discard" || name == "remove" {
+				args[i] = s.goExpr(a)
+			} else {
+				args[i] = s.goSetFrom(t, a)
+			}
+		}
+
+		if m, ok := setUpdateMethods[name]; ok { // s.x_update(o) is s = s.x(o)
+			return recv.Clone().Op("=").Add(recv).Dot(m).Call(args...)
+		}
+
+		if m, ok := setMethods[name]; ok {
+			return recv.Dot(m).Call(args...)
+		}
+	}
+
+	return nil
+}
+
+// a scope for the variables of a comprehension, that are not visible outside of it
+// (not linked as s.next, since no statements are added to it)
+func (s *Scope) comprehensionScope() *Scope {
+	cs := NewScope(s.file, s.imports)
+	cs.funcs = s.funcs
+	cs.classes = s.classes
+	cs.class = s.class
+	cs.prev = s
+	cs.level = s.level + 1
+	return cs
+}
+
+// the type of the elements generated by a comprehension
+func (s *Scope) comprehensionType(generators []ast.Comprehension, elt ast.Expr) *Type {
+	cs := s.comprehensionScope()
+
+	for _, g := range generators {
+		cs.addLoopNames(g.Target, g.Iter)
+	}
+
+	return cs.exprType(elt)
+}
+
+// a new set of type t, with the given elements
+func (s *Scope) goNewSet(t *Type, elts ...jen.Code) *jen.Statement {
+	if t.Elem.Known() {
+		return jen.Qual(goRuntime, "NewSetOf").Index(t.Elem.Go()).Call(elts...)
+	}
+
+	return jen.Qual(goRuntime, "NewSet").Call(elts...)
+}
+
+// set(iterable), or a set from a method argument (set methods accept any iterable)
+func (s *Scope) goSetFrom(t *Type, iterable ast.Expr) *jen.Statement {
+	it := s.exprType(iterable)
+
+	switch {
+	case it.Kind == KindSet && it.Equal(t):
+		return s.goExpr(iterable)
+
+	case it.Kind == KindSet && !t.Elem.Known():
+		return s.goExpr(iterable).Dot("Copy").Call()
+
+	case it.Kind == KindList && (it.Elem.Equal(t.Elem) || !t.Elem.Known() && !it.Elem.Known()):
+		return s.goNewSet(t, s.goExpr(iterable).Op("..."))
+	}
+
+	if t.Elem.Known() {
+		errorf(iterable, "cannot convert %v to a set of %v", s.goExpr(iterable).GoString(), t.Elem.Go().GoString())
+	}
+
+	return jen.Qual(goRuntime, "SetFrom").Call(s.goExpr(iterable))
+}
+
+func (s *Scope) gomprehension(c ast.Comprehension) (*jen.Statement, *jen.Statement) {
+	iter, targets := s.goFor(c.Target, c.Iter, false)
+	if len(c.Ifs) == 0 && targets == nil {
+		return iter, iter
+	}
+
+	cond := jen.Null() // the body is added as a block
+	if len(c.Ifs) > 0 {
+		// multiple conditions are joined with &&, that has precedence over the || of an or
+		test := func(cond ast.Expr) *jen.Statement {
+			if _, ok := cond.(*ast.BoolOp); ok && len(c.Ifs) > 1 {
+				return jen.Parens(s.goTest(cond))
+			}
+			return s.goTest(cond)
+		}
+
+		ccond := test(c.Ifs[0])
+		for _, c := range c.Ifs[1:] {
+			ccond.Add(jen.Op("&&"))
+			ccond.Add(test(c))
+		}
+		cond = jen.If(ccond)
+	}
+
+	iter.Block(s.goLoopTargets(targets, ":="), cond)
+	return iter, cond
+}
+
+// assign the loop variable _t, returned by goFor, to the loop target pattern
+// (or name, for a counted loop assigning a variable defined outside of it).
+// _t is already unpacked to the pattern elements, unless the pattern has a starred element.
+func (s *Scope) goLoopTargets(target ast.Expr, define string) *jen.Statement {
+	if target == nil {
+		return jen.Null()
+	}
+
+	if name, ok := target.(*ast.Name); ok { // a counted loop over range()
+		return s.goExpr(name).Op(define).Id("_t")
+	}
+
+	var u unpacking
+	if elts, _ := patternElts(target); starredIndex(elts) < 0 {
+		s.unpackElements(&u, elts, "_t")
+	} else {
+		s.unpackTarget(&u, target, nil, jen.Id("_t"), typeAny)
+	}
+
+	return u.goPre().Add(s.goExprList(u.targets)).Op(define).List(u.values...)
+}
+
+// print k=v either for function definitions (def=true) or for function call (def=false)
+func (s *Scope) goKvals(kk []*ast.Keyword, def bool) *jen.Statement {
+	return jen.ListFunc(func(g *jen.Group) {
+		if def {
+			for _, k := range kk {
+				g.Add(goId(k.Arg).Commentf("/*=%v*/", s.goExpr(k.Value).GoString()))
+			}
+		} else {
+			for _, k := range kk {
+				g.Commentf("/*%v=*/", string(k.Arg)).Add(s.goExpr(k.Value))
+			}
+		}
+	})
+}
+
+func (s *Scope) goExpr(expr interface{}) *jen.Statement {
+	if verbose {
+		log.Printf("XXX %T %#v\n\n", expr, expr)
+	}
+
+	switch v := expr.(type) {
+	case []ast.Expr:
+		return s.goExprList(v)
+
+	case []*ast.Keyword:
+		return jen.ListFunc(func(g *jen.Group) {
+			for _, k := range v {
+				g.Add(goId(k.Arg).Commentf("/*=%v*/", s.goExpr(k.Value).GoString()))
+			}
+		})
+
+	case *ast.Tuple:
+		return s.goInitialized(goTuple, v.Elts)
+
+	case *ast.List:
+		return s.goInitialized(goList, v.Elts)
+
+	case *ast.Dict:
+		return s.goNewDict(v.Keys, v.Values)
+
+	case *ast.Num:
+		switch n := v.N.(type) {
+		case py.Int:
+			if bigInts {
+				return jen.Qual(goRuntime, "NewInt").Call(jen.Lit(int(n)))
+			}
+			return jen.Lit(int(n))
+
+		case *py.BigInt:
+			b := (*big.Int)(n).String()
+			if bigInts {
+				return jen.Qual(goRuntime, "MustParseInt").Call(jen.Lit(b))
+			}
+
+			errorf(v, "integer literal %v doesn't fit in an int64 (use -bigint)", b)
+			return jen.Id(b)
+
+		case py.Float:
+			return jen.Lit(float64(n))
+
+		case py.Complex:
+			return jen.Lit(complex128(n))
+
+		default:
+			log.Printf("number %v at %v:%v", v.N, v.GetLineno(), v.GetColOffset())
+			panic("invalid number")
+		}
+
+	case ast.Identifier:
+		return goId(v)
+
+	case *ast.NameConstant:
+		switch v.Value {
+		case py.None:
+			return jen.Nil()
+
+		case py.True:
+			return jen.True()
+
+		case py.False:
+			return jen.False()
+
+		default:
+			s, _ := py.Str(v.Value)
+			return jen.Lit(string(s.(py.String)))
+		}
+
+	case *ast.Str:
+		return jen.Lit(string(v.S))
+
+	case *ast.UnaryOp:
+		if bigInts && s.exprType(v.Operand).Kind == KindInt {
+			switch v.Op {
+			case ast.USub:
+				return s.goExpr(v.Operand).Dot("Neg").Call()
+			case ast.UAdd:
+				return s.goExpr(v.Operand)
+			case ast.Invert:
+				return s.goExpr(v.Operand).Dot("Add").Call(jen.Qual(goRuntime, "NewInt").Call(jen.Lit(1))).Dot("Neg").Call()
+			}
+		}
+
+		if v.Op == ast.Invert {
+			return jen.Op("-").Parens(s.goExpr(v.Operand).Op("+").Lit(1))
+		} else if v.Op == ast.Not {
+			return s.goNotTest(v.Operand)
+		} else if v.Op == ast.USub && s.dynamic(v.Operand) {
+			return jen.Qual(goRuntime, "Neg").Call(s.goExpr(v.Operand))
+		} else {
+			return s.goUnary(v.Op).Add(s.goExpr(v.Operand))
+		}
+
+	case *ast.BoolOp:
+		return s.goBoolValue(v)
+
+	case *ast.BinOp:
+		if v.Op == ast.Modulo { // %
+			if s.exprType(v.Left).Kind == KindStr { // this is really a formatting operation
+				printfunc := jen.Qual("fmt", "Sprintf")
+				printfmt := s.goExpr(v.Left)
+				params := s.goExpr(v.Right)
+				if tuple, ok := v.Right.(*ast.Tuple); ok {
+					params = s.goExprList(tuple.Elts)
+				}
+				return printfunc.Params(printfmt, params)
+			}
+		}
+
+		return s.goBinOp(v.Left, v.Op, v.Right)
+
+	case *ast.Compare:
+		stmt := jen.Null()
+
+		left := s.goExpr(v.Left)
+		right := (*jen.Statement)(nil)
+		lexpr := v.Left
+
+		for i, op := range v.Ops {
+			if right != nil {
+				stmt.Op("&&")
+				left = right.Clone()
+				lexpr = v.Comparators[i-1]
+			}
+
+			rexpr := v.Comparators[i]
+			right = s.goExpr(rexpr)
+			lt, rt := s.exprType(lexpr), s.exprType(rexpr)
+
+			if (op == ast.In || op == ast.NotIn) && rt.Kind == KindDict && rt.Key.Known() && rt.Elem.Known() { // map[K]V
+				if op == ast.NotIn {
+					stmt.Op("!")
+				}
+				stmt.Add(jen.Qual(goRuntime, "MapContains").Call(right, left))
+			} else if (op == ast.In || op == ast.NotIn) && (rt.Kind == KindSet || rt.Kind == KindRange || rt.ordered()) {
+				if op == ast.NotIn {
+					stmt.Op("!")
+				}
+				stmt.Add(right.Dot("Contains").Call(left))
+			} else if op == ast.In {
+				stmt.Add(goContains.Clone().Call(right, left))
+			} else if op == ast.NotIn {
+				stmt.Op("!").Add(goContains.Clone().Call(right, left))
+			} else if (op == ast.Eq || op == ast.NotEq) && lt.ordered() && rt.ordered() {
+				if op == ast.NotEq {
+					stmt.Op("!")
+				}
+				stmt.Add(left.Dot("Equal").Call(right))
+			} else if m, ok := setCmps[op]; ok && lt.Kind == KindSet && rt.Kind == KindSet {
+				if op == ast.NotEq {
+					stmt.Op("!")
+				}
+				stmt.Add(left.Dot(m).Call(right))
+			} else if fn, ok := runtimeCmps[op]; ok && s.dynamic(lexpr, rexpr) && !isNone(lexpr) && !isNone(rexpr) {
+				stmt.Add(jen.Qual(goRuntime, fn).Call(left, right))
+			} else if _, ok := runtimeCmps[op]; ok && bigInts && lt.Kind == KindInt && rt.Kind == KindInt {
+				stmt.Add(left.Dot("Cmp").Call(right)).Add(s.goCmpOp(op)).Lit(0)
+			} else {
+				stmt.Add(left)
+				stmt.Add(s.goCmpOp(op))
+				stmt.Add(right)
+			}
+		}
+
+		return stmt
+
+	case *ast.Name:
+		return goId(v.Id)
+
+	case *ast.Attribute:
+		x, b, a := strAttribute(v)
+		a = rename(a)
+
+		if x != nil {
+			return s.goExpr(x).Dot(a)
+		}
+
+		switch {
+		case b == "re" && a == "compile":
+			return jen.Qual("regexp", "MustCompile")
+
+		case b == "re" && a == "match":
+			return jen.Qual("regexp", "MatchString")
+
+		case b == "sys" && a == "argv":
+			return jen.Qual("os", "Args")
+
+		case b == "sys" && a == "stdin":
+			return jen.Qual("os", "Stdin")
+
+		case b == "sys" && a == "stdout":
+			return jen.Qual("os", "Stdout")
+
+		case b == "sys" && a == "stderr":
+			return jen.Qual("os", "Stderr")
+
+		case b == "sys.stdin":
+			return jen.Qual("os", "Stdin").Dot(a)
+
+		case b == "sys.stdout":
+			return jen.Qual("os", "Stdout").Dot(a)
+
+		case b == "sys.stderr":
+			return jen.Qual("os", "Stderr").Dot(a)
+		}
+
+		if imp, ok := s.imports[b]; ok {
+			return jen.Qual(imp, a)
+		}
+
+		return jen.Id(b).Dot(a)
+
+	case *ast.Subscript:
+		return s.goSlice(v.Value, v.Slice)
+
+	case *ast.Call:
+		if l, ok := v.Func.(*ast.Lambda); ok && v.Starargs == nil && len(v.Keywords) == 0 { // (lambda x: ...)(a)
+			return s.goLambda(l, s.argTypes(v.Args), nil).Call(s.goExprList(v.Args))
+		}
+		if stmt := s.goBuiltinCall(v); stmt != nil {
+			return stmt
+		}
+		if bigInts && s.goIntCall(v) {
+			return jen.Qual(goRuntime, "NewInt").Call(s.goCall(v))
+		}
+		if s.callResults(v) != nil { // the results are used as a single value
+			return jen.Qual(goRuntime, "Pack").Call(s.goCall(v))
+		}
+		return s.goCall(v)
+
+	case *ast.Lambda:
+		return s.goLambda(v, nil, nil)
+
+	case *ast.IfExp:
+		if stmt := s.goLowered(v); stmt != nil {
+			return stmt
+		}
+		return s.goIfElse(v)
+
+	case *ast.ListComp:
+		if stmt := s.goLowered(v); stmt != nil {
+			return stmt
+		}
+		return s.goLoweringFunc(v, "lc")
+
+	case *ast.Set:
+		return s.goNewSet(s.exprType(v), s.goExprList(v.Elts))
+
+	case *ast.SetComp:
+		if stmt := s.goLowered(v); stmt != nil {
+			return stmt
+		}
+		return s.goLoweringFunc(v, "sc")
+
+	case *ast.DictComp:
+		if stmt := s.goLowered(v); stmt != nil {
+			return stmt
+		}
+		return s.goLoweringFunc(v, "mm")
+
+	case *ast.GeneratorExp:
+		// single-use, as python generators
+		return jen.Qual(goRuntime, "NewGenerator").Call(s.goGeneratorSeq(v))
+	}
+
+	return unknown("EXPR", expr)
+}
+
+// an iter.Seq running the loops of the generator expression, that stop when the consumer does
+func (s *Scope) goGeneratorSeq(g *ast.GeneratorExp) *jen.Statement {
+	elem := s.exprType(g).elemType().Go()
+	return jen.Qual("iter", "Seq").Index(elem).Call(jen.Func().Params(jen.Id("yield").Func().Params(elem).Bool()).Block(
+		s.goComprehension(g.Generators, []ast.Expr{g.Elt}, func(cs *Scope) jen.Code {
+			return jen.If(jen.Op("!").Id("yield").Call(cs.goExpr(g.Elt))).Block(jen.Return())
+		})))
+}
+
+// the elements of an iterator (a generator expression) as an iter.Seq, for range
+func (s *Scope) goIterSeq(iterable ast.Expr) *jen.Statement {
+	if g, ok := iterable.(*ast.GeneratorExp); ok { // consumed here, it doesn't need a Generator
+		return s.goGeneratorSeq(g)
+	}
+
+	return s.goExpr(iterable).Dot("All").Call()
+}
+
+func goId(id ast.Identifier) *jen.Statement {
+	return jen.Id(rename(string(id)))
+}
+
+func (s *Scope) goFunctionArguments(args *ast.Arguments, skipReceiver bool, sig *Signature) (*jen.Statement, *ast.Arg) {
+	var recv *ast.Arg
+
+	if args == nil {
+		return jen.Null(), recv
+	}
+
+	var params []jen.Code
+
+	aargs := args.Args
+	if skipReceiver && len(aargs) > 0 {
+		recv, aargs = aargs[0], aargs[1:]
+	}
+
+	if sig != nil && len(sig.optional) > 0 {
+		// the optional parameters are passed in a struct (see goDefaults)
+		for _, arg := range sig.required {
+			s.addName(arg.Arg, paramType(arg, nil))
+			params = append(params, goId(arg.Arg).Add(s.goParamType(arg, nil)))
+		}
+
+		for i, arg := range sig.optional {
+			s.addName(arg.Arg, paramType(arg, sig.defaults[i]))
+		}
+
+		params = append(params, jen.Id(sig.argsParam()).Id(sig.argsType()))
+	} else {
+		for _, arg := range aargs {
+			s.addName(arg.Arg, paramType(arg, nil))
+			params = append(params, goId(arg.Arg).Add(s.goParamType(arg, nil)))
+		}
+
+		for i, arg := range args.Kwonlyargs {
+			s.addName(arg.Arg, paramType(arg, nil))
+
+			p := goId(arg.Arg).Add(s.goParamType(arg, nil))
+			if i < len(args.KwDefaults) && args.KwDefaults[i] != nil {
+				p.Commentf("/*=%v*/", s.goExpr(args.KwDefaults[i]).GoString())
+			}
+			params = append(params, p)
+		}
+	}
+
+	// **kwargs is a dictionary (with the annotation as the type of the values)
+	if args.Kwarg != nil {
+		t := kwargsOf(paramType(args.Kwarg, nil))
+		s.addName(args.Kwarg.Arg, t)
+		params = append(params, goId(args.Kwarg.Arg).Add(t.Go()))
+	}
+
+	// *args is variadic, so it needs to be last
+	if args.Vararg != nil {
+		s.addName(args.Vararg.Arg, listOf(paramType(args.Vararg, nil)))
+
+		p := goId(args.Vararg.Arg).Op("...")
+		if args.Vararg.Annotation != nil {
+			p.Add(s.goExpr(args.Vararg.Annotation))
+		} else {
+			p.Add(goAny)
+		}
+
+		params = append(params, p)
+	}
+
+	return jen.List(params...), recv
+}
+
+// the scope of the body of the lambda v, with its parameters typed as params
+// (for the parameters without a type, the type of the default value or Any)
+func (s *Scope) lambdaScope(v *ast.Lambda, params []*Type) *Scope {
+	ls := s.comprehensionScope()
+	if v.Args == nil {
+		return ls
+	}
+
+	nreq := len(v.Args.Args) - len(v.Args.Defaults)
+	for i, arg := range v.Args.Args {
+		var def ast.Expr
+		if i >= nreq {
+			def = v.Args.Defaults[i-nreq]
+		}
+
+		if t := paramType(arg, def); i < len(params) && params[i] != nil && !t.Known() {
+			ls.addName(arg.Arg, params[i])
+		} else {
+			ls.addName(arg.Arg, t)
+		}
+	}
+
+	if v.Args.Vararg != nil {
+		ls.addName(v.Args.Vararg.Arg, typeList)
+	}
+
+	return ls
+}
+
+// the type of the lambda v. The parameters with a default and *args can't be expressed
+// in a Go function type: they are passed as a final variadic ...Any parameter
+func (s *Scope) lambdaType(v *ast.Lambda) *Type {
+	ls := s.lambdaScope(v, nil)
+
+	var params []*Type
+	variadic := false
+	if v.Args != nil {
+		for _, arg := range v.Args.Args[:len(v.Args.Args)-len(v.Args.Defaults)] {
+			params = append(params, ls.varType(string(arg.Arg)))
+		}
+
+		if variadic = len(v.Args.Defaults) > 0 || v.Args.Vararg != nil; variadic {
+			params = append(params, typeAny)
+		}
+	}
+
+	t := funcOf(params, ls.exprType(v.Body))
+	t.Variadic = variadic
+	return t
+}
+
+// a function literal for the lambda v, with the parameters typed as params and the given result type
+// (both can be nil, and the result type is inferred from the body if not known).
+//
+// The default values are evaluated when the lambda is defined, as python does: they are passed to
+// a function that returns the literal. The parameters with a default are optional arguments of
+// the literal (the first of the final ...Any parameter, followed by *args), unless params has no room
+// for them (the lambda is passed as a function with only the required parameters).
+func (s *Scope) goLambda(v *ast.Lambda, params []*Type, result *Type) *jen.Statement {
+	ls := s.lambdaScope(v, params)
+	if !result.Known() && (result == nil || result.Kind != KindNone) {
+		result = ls.exprType(v.Body)
+	}
+
+	var required, defaults []*ast.Arg
+	if v.Args != nil {
+		if len(v.Args.Kwonlyargs) > 0 || v.Args.Kwarg != nil {
+			errorf(v, "keyword-only parameters and **kwargs are not supported in lambdas")
+		}
+
+		nreq := len(v.Args.Args) - len(v.Args.Defaults)
+		required, defaults = v.Args.Args[:nreq], v.Args.Args[nreq:]
+	}
+
+	var goParams, goTypes []jen.Code
+	for _, arg := range required {
+		t := ls.varType(string(arg.Arg)).Go()
+		goParams = append(goParams, goId(arg.Arg).Add(t))
+		goTypes = append(goTypes, t.Clone())
+	}
+
+	optional := len(defaults) > 0 && (params == nil || len(params) > len(required))
+
+	var rest jen.Code
+	switch {
+	case v.Args != nil && v.Args.Vararg != nil:
+		rest = goId(v.Args.Vararg.Arg)
+	case optional:
+		rest = jen.Id(s.generatedName("_opt"))
+	}
+
+	if rest != nil {
+		goParams = append(goParams, jen.Add(rest).Op("...").Add(goAny))
+		goTypes = append(goTypes, jen.Op("...").Add(goAny))
+	}
+
+	// the optional arguments (only the ones used in the body, since Go doesn't allow unused variables)
+	var prologue []jen.Code
+	if optional {
+		used := map[string]bool{}
+		ast.Walk(v.Body, func(node ast.Ast) bool {
+			if name, ok := node.(*ast.Name); ok {
+				used[string(name.Id)] = true
+			}
+			return true
+		})
+
+		for i, arg := range defaults {
+			if used[string(arg.Arg)] {
+				prologue = append(prologue, goId(arg.Arg).Op(":=").Qual(goRuntime, "OptionalArg").Call(rest, jen.Lit(i), goId(arg.Arg)))
+			}
+		}
+
+		if v.Args.Vararg != nil { // *args gets what's left after the optional arguments
+			prologue = append(prologue, jen.Add(rest).Op("=").Add(rest).Index(jen.Id("min").Call(jen.Len(rest), jen.Lit(len(defaults))).Op(":")))
+		}
+	}
+
+	fn := jen.Func().Params(goParams...)
+	ftype := jen.Func().Params(goTypes...)
+	if result.Kind == KindNone { // a callback that doesn't return a value
+		fn.Block(append(prologue, ls.goExpr(v.Body))...)
+	} else {
+		fn.Add(result.Go()).Block(append(prologue, jen.Return(ls.goExpr(v.Body)))...)
+		ftype.Add(result.Go())
+	}
+
+	if len(defaults) == 0 {
+		return fn
+	}
+
+	var captured, values []jen.Code
+	for i, arg := range defaults {
+		captured = append(captured, goId(arg.Arg).Add(ls.varType(string(arg.Arg)).Go()))
+		values = append(values, s.goExpr(v.Args.Defaults[i]))
+	}
+
+	return jen.Func().Params(captured...).Add(ftype).Block(jen.Return(fn)).Call(values...)
+}
+
+// the types of the arguments of a call
+func (s *Scope) argTypes(args []ast.Expr) []*Type {
+	types := make([]*Type, len(args))
+	for i, arg := range args {
+		types[i] = s.exprType(arg)
+	}
+
+	return types
+}
+
+// translate expr as a value of type t: a lambda is translated to a function literal with the
+// parameter and result types of t (a function type), any other expression as usual
+func (s *Scope) goExprAs(expr ast.Expr, t *Type) *jen.Statement {
+	if l, ok := expr.(*ast.Lambda); ok && t != nil && t.Kind == KindFunc {
+		return s.goLambda(l, t.Params, t.Elem)
+	}
+
+	return s.goExpr(expr)
+}
+
+// assign the optional arguments (passed as a struct) to local variables.
+// Only the arguments used in the function body are assigned, since Go doesn't allow unused variables
+func (s *Scope) goOptionalArguments(sig *Signature, body []ast.Stmt) *jen.Statement {
+	if sig == nil || len(sig.optional) == 0 {
+		return jen.Null()
+	}
+
+	used := map[string]bool{}
+	for _, stmt := range body {
+		ast.Walk(stmt, func(node ast.Ast) bool {
+			if name, ok := node.(*ast.Name); ok {
+				used[string(name.Id)] = true
+			}
+			return true
+		})
+	}
+
+	var names, values []jen.Code
+	for _, arg := range sig.optional {
+		if used[string(arg.Arg)] {
+			names = append(names, goId(arg.Arg))
+			values = append(values, jen.Id(sig.argsParam()).Dot(rename(string(arg.Arg))))
+		}
+	}
+
+	if len(names) == 0 {
+		return jen.Null()
+	}
+
+	return jen.List(names...).Op(":=").List(values...).Line()
+}
+
+func strAttribute(attr *ast.Attribute) (ast.Expr, string, string) {
+	var expr ast.Expr
+	var base string
+
+	switch v := attr.Value.(type) {
+	case *ast.Attribute:
+		_, b, a := strAttribute(v)
+		base = b + "." + a
+		expr = nil
+
+	case *ast.Name:
+		base = string(v.Id)
+		expr = nil
+
+	default:
+		expr = attr.Value
+	}
+
+	return expr, base, string(attr.Attr)
+}
+
+func (s *Scope) goCallParams(params ...ast.Expr) *jen.Statement {
+	return jen.ParamsFunc(func(g *jen.Group) {
+		for _, p := range params {
+			g.Add(s.goExpr(p))
+		}
+	})
+}
+
+func (s *Scope) goCall(call *ast.Call) *jen.Statement {
+	if stmt := s.goListCall(call); stmt != nil {
+		return stmt
+	}
+
+	if stmt := s.goSetCall(call); stmt != nil {
+		return stmt
+	}
+
+	if stmt := s.goDictCall(call); stmt != nil {
+		return stmt
+	}
+
+	if stmt := s.goMapCall(call); stmt != nil {
+		return stmt
+	}
+
+	cfunc := s.goExpr(call.Func)
+
+	switch ff := call.Func.(type) {
+	case *ast.Name:
+		switch string(ff.Id) {
+		case "print":
+			cfunc = jen.Qual("fmt", "Println") // check for print parameters, could be fmt.Print, fmt.Fprint, etc.
+
+		case "open":
+			cfunc = jen.Qual("os", "Open") // could also be os.OpenFile
+
+		case "isinstance": // isinstance(obj, type)
+			if isInstance(call) {
+				if stmt := s.goLowered(call); stmt != nil {
+					return stmt
+				}
+				return s.goLoweringFunc(call, "ok")
+			}
+
+		case "type":
+			cfunc = jen.Qual("reflect", "Type")
+		}
+
+	case *ast.Attribute:
+		switch string(ff.Attr) {
+		case "read":
+			cfunc = s.goExpr(ff.Value).Dot("Read")
+
+		case "write":
+			cfunc = s.goExpr(ff.Value).Dot("Write")
+
+		case "close":
+			cfunc = s.goExpr(ff.Value).Dot("Close")
+
+		case "items": // as in `for k, v in dict(a=1).items()`
+			return s.goExpr(ff.Value) // remove items
+
+		case "append":
+			if len(call.Args) == 1 {
+				return s.goExpr(ff.Value).Op("=").Id("append").
+					Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
+			}
+
+		case "upper":
+			return jen.Qual("strings", "ToUpper").Call(s.goExpr(ff.Value))
+
+		case "lower":
+			return jen.Qual("strings", "ToLower").Call(s.goExpr(ff.Value))
+
+		case "startswith":
+			if len(call.Args) == 1 {
+				return jen.Qual("strings", "HasPrefix").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
+			}
+
+		case "endswith":
+			if len(call.Args) == 1 {
+				return jen.Qual("strings", "HasSuffix").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
+			}
+
+		case "strip":
+			if len(call.Args) == 0 {
+				return jen.Qual("strings", "TrimSpace").Call(s.goExpr(ff.Value))
+			} else if len(call.Args) == 1 {
+				return jen.Qual("strings", "Trim").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
+			}
+
+		case "lstrip":
+			if len(call.Args) == 0 {
+				return jen.Qual(goRuntime, "TrimLeft").Call(s.goExpr(ff.Value))
+			} else if len(call.Args) == 1 {
+				return jen.Qual("strings", "TrimLeft").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
+			}
+
+		case "rstrip":
+			if len(call.Args) == 0 {
+				return jen.Qual(goRuntime, "TrimRight").Call(s.goExpr(ff.Value))
+			} else if len(call.Args) == 1 {
+				return jen.Qual("strings", "TrimRight").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
+			}
+
+		case "split":
+			if len(call.Args) == 0 {
+				return jen.Qual(goRuntime, "Splits").Call(s.goExpr(ff.Value))
+			} else if len(call.Args) == 1 {
+				return jen.Qual("strings", "Split").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
+			} else if len(call.Args) == 2 {
+				return jen.Qual("strings", "SplitN").Call(s.goExpr(ff.Value),
+					s.goExpr(call.Args[0]),
+					s.goExpr(call.Args[1]).Op("+").Lit(1))
+			}
+
+		case "join":
+			if len(call.Args) == 1 {
+				elems := s.goExpr(call.Args[0])
+				if t := s.exprType(call.Args[0]); t.Kind == KindIter && t.elemType().Kind == KindStr {
+					elems, _, _ = s.goElements(call.Args[0])
+				}
+				return jen.Qual("strings", "Join").Call(elems, s.goExpr(ff.Value))
+			}
+
+		case "replace":
+			if len(call.Args) == 2 {
+				return jen.Qual("strings", "Replace").Call(s.goExpr(ff.Value),
+					s.goExpr(call.Args[0]),
+					s.goExpr(call.Args[1]),
+					jen.Lit(-1))
+			} else if len(call.Args) == 3 {
+				return jen.Qual("strings", "Replace").Call(s.goExpr(ff.Value),
+					s.goExpr(call.Args[0]),
+					s.goExpr(call.Args[1]),
+					s.goExpr(call.Args[2]))
+			}
+
+		case "count":
+			if len(call.Args) == 1 {
+				return jen.Qual("strings", "Count").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
+			}
+
+		case "isspace":
+			if len(call.Args) == 0 {
+				return jen.Qual(goRuntime, "IsSpace").Call(s.goExpr(ff.Value))
+			}
+
+		case "isalpha":
+			if len(call.Args) == 0 {
+				return jen.Qual(goRuntime, "IsAlpha").Call(s.goExpr(ff.Value))
+			}
+
+		case "isdigit", "isnumeric":
+			if len(call.Args) == 0 {
+				return jen.Qual(goRuntime, "IsDigit").Call(s.goExpr(ff.Value))
+			}
+
+		case "isupper":
+			if len(call.Args) == 0 {
+				return jen.Qual(goRuntime, "IsUpper").Call(s.goExpr(ff.Value))
+			}
+
+		case "islower":
+			if len(call.Args) == 0 {
+				return jen.Qual(goRuntime, "IsLower").Call(s.goExpr(ff.Value))
+			}
+
+		case "reverse":
+			if len(call.Args) == 0 {
+				return jen.Qual(goRuntime, "Reverse").Call(s.goExpr(ff.Value))
+			}
+		}
+
+		if name, ok := ff.Value.(*ast.Name); ok {
+			switch {
+			case string(name.Id) == "sys" && string(ff.Attr) == "exit":
+				ret := jen.Lit(-1)
+				if len(call.Args) > 0 {
+					ret = s.goExpr(call.Args[0])
+				}
+				return jen.Qual("os", "Exit").Call(ret)
+
+			case string(name.Id) == "time" && string(ff.Attr) == "sleep" && len(call.Args) == 1:
+				tt := jen.Qual("time", "Duration").Parens(
+					s.goExpr(call.Args[0]).Op("*").Float64().Parens(jen.Qual("time", "Second")))
+				return jen.Qual("time", "Sleep").Call(tt)
+
+			case string(name.Id) == "time" && string(ff.Attr) == "time" && len(call.Args) == 0:
+				return jen.Qual("time", "Now").Call()
+			}
+		}
+	}
+
+	var args []jen.Code
+
+	if sig := s.callSignature(call); sig != nil {
+		return cfunc.Call(s.goSignatureArgs(sig, call)...)
+	}
+
+	for _, arg := range call.Args {
+		args = append(args, s.goExpr(arg))
+	}
+
+	if len(call.Keywords) > 0 {
+		args = append(args, s.goKvals(call.Keywords, false))
+	}
+
+	if call.Kwargs != nil {
+		args = append(args, s.goExpr(call.Kwargs))
+	}
+
+	if call.Starargs != nil {
+		args = append(args, s.goExpr(call.Starargs).Op("..."))
+	}
+
+	return cfunc.Call(args...)
+}
+
+// iterate over a dictionary: a runtime.OrderedDict or a Go map (nil if iter is not a dictionary)
+func (s *Scope) goForDict(target, iter ast.Expr, define string) *jen.Statement {
+	method := ""
+	dict := iter
+
+	if c, ok := iter.(*ast.Call); ok && len(c.Args) == 0 {
+		if attr, ok := c.Func.(*ast.Attribute); ok {
+			method = string(attr.Attr)
+			dict = attr.Value
+		}
+	}
+
+	t := s.exprType(dict)
+	if t.Kind != KindDict {
+		return nil
+	}
+
+	all := s.goExpr(dict) // Go maps can be iterated directly
+	if t.ordered() {
+		all.Dot("All").Call()
+	}
+
+	n := lenExpr(target)
+
+	switch {
+	case n == 1 && (method == "" || method == "keys"):
+		return jen.For(s.goExpr(target).Op(define).Range().Add(all))
+
+	case n == 1 && method == "values":
+		return jen.For(jen.List(jen.Op("_"), s.goExpr(target)).Op(define).Range().Add(all))
+
+	case n == 2 && method == "items":
+		return jen.For(s.goExprOrList(target).Op(define).Range().Add(all))
+	}
+
+	return nil
+}
+
+// the types of n loop variables iterating over iter
+func (s *Scope) forTypes(n int, iter ast.Expr) []*Type {
+	types := make([]*Type, n)
+	for i := range types {
+		types[i] = typeAny
+	}
+
+	if c, ok := iter.(*ast.Call); ok {
+		if name, ok := c.Func.(*ast.Name); ok {
+			switch {
+			case string(name.Id) == "range" && n == 1:
+				types[0] = typeInt
+				return types
+
+			case string(name.Id) == "enumerate" && n == 2 && len(c.Args) >= 1:
+				types[0] = typeInt
+				types[1] = s.elementsType(c.Args[0])
+				return types
+
+			case string(name.Id) == "zip" && n == 2 && len(c.Args) == 2:
+				types[0] = s.elementsType(c.Args[0])
+				types[1] = s.elementsType(c.Args[1])
+				return types
+
+			case string(name.Id) == "reversed" && n == 1 && len(c.Args) == 1:
+				types[0] = s.elementsType(c.Args[0])
+				return types
+			}
+		}
+	}
+
+	switch t := s.exprType(iter); {
+	case (t.Kind == KindList || t.Kind == KindRange || t.Kind == KindStr || t.Kind == KindIter) && n == 1:
+		types[0] = t.elemType()
+
+	case t.Kind == KindDict && n == 2 && t.Key != nil:
+		types[0], types[1] = t.Key, t.Elem
+	}
+
+	return types
+}
+
+// translate `for target in iter`. If reuse is true and the target variables
+// are already defined in the function they are assigned, instead of declared in the loop.
+func (s *Scope) goFor(target, iter ast.Expr, reuse bool) (*jen.Statement, ast.Expr) {
+	define := ":="
+	if reuse && s.allDefined(target) {
+		define = "="
+	}
+
+	_, flat := exprIds(target)
+	if define == ":=" { // variables already defined keep their type
+		s.addLoopNames(target, iter)
+	}
+
+	if c, ok := iter.(*ast.Call); ok { // check for "for x in range(n)"
+		//
+		// for x in range(y)
+		//
+		if n, ok := c.Func.(*ast.Name); ok && string(n.Id) == "range" {
+			if len(c.Args) < 1 || len(c.Args) > 3 {
+				log.Printf("at %v:%v", iter.GetLineno(), iter.GetColOffset())
+				panic("range expects 1 to 3 arguments")
+			}
+
+			// a variable defined outside of the loop is assigned at each iteration from the loop variable _t,
+			// so that after the loop it has the last value (and it's not changed by an empty loop)
+			t := s.goExpr(target)
+			var targets ast.Expr
+			if define == "=" {
+				t, targets, define = jen.Id("_t"), target, ":="
+			}
+
+			// the direction of the loop depends on the sign of the step:
+			// if it's not a constant let runtime.Range work it out
+			n := 1
+			if len(c.Args) == 3 {
+				var ok bool
+				if n, ok = intConst(c.Args[2]); !ok {
+					return jen.For(t.Op(define).Range().Add(goRangeAll(s.goExpr(iter)))), targets
+				}
+				if n == 0 {
+					errorf(iter, "range() arg 3 must not be zero")
+				}
+			}
+
+			start := jen.Lit(0)
+			step := jen.Lit(1)
+			cmp := "<"
+
+			var stop jen.Code
+
+			// in -bigint mode the loop variable is a runtime.Int, otherwise a Go int
+			value := func(expr ast.Expr) *jen.Statement {
+				switch {
+				case !bigInts:
+					return s.goIntValue(expr)
+
+				case !s.exprType(expr).Known():
+					return jen.Qual(goRuntime, "NewInt").Call(s.goIntValue(expr))
+				}
+				return s.goExpr(expr)
+			}
+
+			if len(c.Args) == 1 {
+				stop = value(c.Args[0])
+			} else {
+				start = value(c.Args[0])
+				stop = value(c.Args[1])
+
+				if len(c.Args) > 2 {
+					step = value(c.Args[2])
+				}
+			}
+
+			if n < 0 {
+				cmp = ">"
+			}
+
+			if bigInts {
+				if len(c.Args) < 3 {
+					step = jen.Qual(goRuntime, "NewInt").Call(step)
+				}
+				if len(c.Args) < 2 {
+					start = jen.Qual(goRuntime, "NewInt").Call(start)
+				}
+
+				return jen.For(t.Clone().Op(define).Add(start),
+					t.Clone().Dot("Cmp").Call(stop).Op(cmp).Lit(0),
+					t.Clone().Op("=").Add(t.Clone()).Dot("Add").Call(step)), targets
+			}
+
+			if n < 0 {
+				return jen.For(t.Clone().Op(define).Add(start),
+					t.Clone().Op(cmp).Add(stop),
+					t.Clone().Op("-=").Lit(-n)), targets
+			}
+
+			return jen.For(t.Clone().Op(define).Add(start),
+				t.Clone().Op(cmp).Add(stop),
+				t.Clone().Op("+=").Add(step)), targets
+		}
+
+		//
+		// for i, v in enumerate(l)
+		//
+		if n, ok := c.Func.(*ast.Name); ok && string(n.Id) == "enumerate" && len(c.Args) == 1 && len(c.Keywords) == 0 &&
+			lenExpr(target) == 2 && flat && s.isBuiltin("enumerate") &&
+			(s.exprType(c.Args[0]).Kind == KindList || s.exprType(c.Args[0]).Kind == KindTuple) {
+			return jen.For(s.goExprOrList(target).Op(define).Range().Add(s.goExpr(c.Args[0]))), nil
+		}
+
+		// the builtins that can be iterated without building a list
+		if n, ok := c.Func.(*ast.Name); ok && s.isBuiltin(string(n.Id)) && c.Starargs == nil && c.Kwargs == nil {
+			// a Go slice with the elements of expr
+			slice := func(expr ast.Expr) *jen.Statement {
+				elems, _, _ := s.goElements(expr)
+				return elems
+			}
+
+			switch name := string(n.Id); {
+			//
+			// for i, v in enumerate(l, start)
+			//
+			case name == "enumerate" && lenExpr(target) == 2 && flat && len(c.Args) >= 1 && len(c.Args)+len(c.Keywords) <= 2:
+				start := callKeyword(c, "start")
+				if len(c.Args) == 2 {
+					start = c.Args[1]
+				}
+
+				if start == nil && len(c.Keywords) == 0 {
+					start = &ast.Num{N: py.Int(0)}
+				}
+
+				if start != nil {
+					return jen.For(s.goExprOrList(target).Op(define).Range().Qual(goRuntime, "Enumerated").Call(
+						slice(c.Args[0]), s.goIntValue(start))), nil
+				}
+
+			//
+			// for a, b in zip(x, y)
+			//
+			case name == "zip" && lenExpr(target) == 2 && flat && len(c.Args) == 2 && len(c.Keywords) == 0:
+				return jen.For(s.goExprOrList(target).Op(define).Range().Qual(goRuntime, "Zip2").Call(
+					slice(c.Args[0]), slice(c.Args[1]))), nil
+
+			//
+			// for v in reversed(l)
+			//
+			case name == "reversed" && lenExpr(target) == 1 && len(c.Args) == 1 && len(c.Keywords) == 0:
+				return jen.For(jen.List(jen.Op("_"), s.goExpr(target)).Op(define).Range().Qual("slices", "Backward").Call(
+					slice(c.Args[0]))), nil
+			}
+		}
+
+		//
+		// for v in iterator
+		//
+		//if lenExpr(target) <= 1 {
+		//    return jen.For(jen.List(jen.Op("_"), s.goExpr(target)).Op(define).Range().Add(s.goExpr(iter))), nil
+		//}
+	}
+
+	// for k in d, for k in d.keys(), for v in d.values(), for k, v in d.items()
+	if stmt := s.goForDict(target, iter, define); stmt != nil {
+		return stmt, nil
+	}
+
+	// for x in iterable
+	// for k, v in dict
+	// for a,b,c in tuple iterable
+
+	it := s.exprType(iter)
+
+	// x.items() of an unknown x is translated to x, expected to be a Go map
+	items := false
+	if c, ok := iter.(*ast.Call); ok && len(c.Args) == 0 {
+		if attr, ok := c.Func.(*ast.Attribute); ok && attr.Attr == "items" {
+			items = true
+		}
+	}
+
+	switch n := lenExpr(target); {
+	case n == 0:
+		log.Fatalf("for without target: %#v", target)
+
+	case n == 1:
+		switch it.Kind {
+		case KindList, KindTuple:
+			return jen.For(jen.List(jen.Op("_"), s.goExpr(target)).Op(define).Range().Add(s.goExpr(iter))), nil
+
+		case KindSet: // the elements are the values (the keys are their hash keys)
+			return jen.For(jen.List(jen.Op("_"), s.goExpr(target)).Op(define).Range().Add(s.goExpr(iter))), nil
+
+		case KindRange:
+			return jen.For(s.goExpr(target).Op(define).Range().Add(goRangeAll(s.goExpr(iter)))), nil
+
+		case KindStr:
+			return jen.For(s.goExpr(target).Op(define).Range().Qual(goRuntime, "Chars").Call(s.goExpr(iter))), nil
+
+		case KindIter:
+			return jen.For(s.goExpr(target).Op(define).Range().Add(s.goIterSeq(iter))), nil
+		}
+
+		// not statically a slice or a map: use the iteration protocol
+		return jen.For(s.goExpr(target).Op(define).Range().Qual(goRuntime, "Iterate").Call(s.goExpr(iter))), nil
+
+	case n == 2 && flat && (it.Kind == KindDict || items):
+		return jen.For(s.goExprOrList(target).Op(define).Range().Add(s.goExpr(iter))), nil
+
+	default:
+		// each element is unpacked into the tuple _t, that is assigned to the targets
+		t := target.(*ast.Tuple)
+		if starredIndex(t.Elts) >= 0 { // unpacked by goLoopTargets
+			return jen.For(jen.Id("_t").Op(":=").Range().Qual(goRuntime, "Iterate").Call(s.goExpr(iter))), target
+		}
+
+		loopVar := jen.Id("_t")
+		if flat {
+			loopVar.Commentf("/* %s */", s.strExprList(t.Elts))
+		}
+
+		return jen.For(loopVar.Op(":=").Range().Qual(goRuntime, "Unpacked").Call(s.goExpr(iter), jen.Lit(n))), target
+	}
+
+	return nil, nil // shouldn't get here
+}
+
+// the loop body has a break for the loop itself (and not for a nested loop)
+func hasBreak(body []ast.Stmt) bool {
+	for _, stmt := range body {
+		switch v := stmt.(type) {
+		case *ast.Break:
+			return true
+
+		case *ast.If:
+			if hasBreak(v.Body) || hasBreak(v.Orelse) {
+				return true
+			}
+
+		case *ast.For: // the else clause is not part of the nested loop
+			if hasBreak(v.Orelse) {
+				return true
+			}
+
+		case *ast.While:
+			if hasBreak(v.Orelse) {
+				return true
+			}
+
+		case *ast.With:
+			if hasBreak(v.Body) {
+				return true
+			}
+
+		case *ast.Try:
+			if hasBreak(v.Body) || hasBreak(v.Orelse) || hasBreak(v.Finalbody) {
+				return true
+			}
+			for _, h := range v.Handlers {
+				if hasBreak(h.Body) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// translate break (or continue, if brk is false) for the innermost loop.
+//
+// In a try body (a closure) set a flag and return, the flag is checked after the try statement;
+// in an except clause (a switch case) break needs the loop label;
+// for a loop with an else clause break also sets the flag that skips the else clause.
+func (s *Scope) goLoopExit(brk bool) *jen.Statement {
+	inSwitch := false
+
+	for curr := s; curr != nil; curr = curr.prev {
+		c := curr.control
+		if c == nil {
+			if curr.names != nil { // a function body
+				break
+			}
+			continue
+		}
+
+		switch c.kind {
+		case controlExcept:
+			inSwitch = true
+
+		case controlTry:
+			flag, prefix := &c.breakFlag, "_break"
+			if !brk {
+				flag, prefix = &c.continueFlag, "_continue"
+			}
+			if *flag == "" {
+				*flag = s.generatedName(prefix)
+			}
+			return jen.Id(*flag).Op("=").True().Line().Return(goException.Clone().Values())
+
+		case controlLoop:
+			if !brk {
+				return jen.Continue()
+			}
+
+			stmt := jen.Null()
+			if c.broken != "" {
+				stmt = jen.Id(c.broken).Op("=").True().Line()
+			}
+			if inSwitch {
+				if c.label == "" {
+					c.label = s.generatedName("_loop")
+				}
+				return stmt.Break().Id(c.label)
+			}
+			return stmt.Break()
+		}
+	}
+
+	if brk {
+		return jen.Break()
+	}
+	return jen.Continue()
+}
+
+// the control of a loop: a loop with an else clause and a break needs a flag
+func (s *Scope) newLoopControl(body, orelse []ast.Stmt) *loopControl {
+	c := &loopControl{kind: controlLoop}
+	if len(orelse) > 0 && hasBreak(body) {
+		c.broken = s.generatedName("_broken")
+	}
+
+	return c
+}
+
+// add a loop statement, with its label and else clause
+func (s *Scope) addLoop(loop *jen.Statement, control *loopControl, orelse []ast.Stmt) {
+	if control.label != "" {
+		loop = jen.Id(control.label).Op(":").Line().Add(loop)
+	}
+
+	if control.broken != "" {
+		loop = jen.Id(control.broken).Op(":=").False().Line().Add(loop)
+	}
+
+	if len(orelse) > 0 {
+		// the else clause is executed when the loop is not terminated by break
+		ss := s.Push()
+		body := ss.parseBody("", orelse)
+		ss.Pop(false)
+
+		if control.broken != "" {
+			loop.Line().If(jen.Op("!").Id(control.broken)).Block(body)
+		} else {
+			loop.Line().Add(body)
+		}
+	}
+
+	s.Add(loop)
+}
+
+// Kind is the kind of a python value, as far as we can tell at translation time
+type Kind int
+
+const (
+	KindAny Kind = iota // not known: runtime.Any
+	KindNone
+	KindBool
+	KindInt
+	KindFloat
+	KindComplex
+	KindStr
+	KindList
+	KindTuple
+	KindDict
+	KindSet
+	KindRange
+	KindObject // an instance of a class defined in the module
+	KindFunc   // a function (a lambda or a Callable annotation)
+	KindIter   // an iterator (a generator expression, a *runtime.Generator in Go)
+)
+
+// Type is the static type of a python expression, used to generate typed Go code when possible
+type Type struct {
+	Kind
+	Elem *Type // the type of the elements (of a list or set) or of the values (of a dict), if known
+	Key  *Type // the type of the keys (of a dict), if known (a dict without key type is a runtime.OrderedDict)
+
+	Class string // the class name (for objects)
+
+	Params   []*Type // the types of the parameters (of a function, where Elem is the type of the result)
+	Variadic bool    // the last parameter is variadic (...Any, for the optional arguments of a lambda)
+}
+
+var (
+	typeAny     = &Type{Kind: KindAny}
+	typeNone    = &Type{Kind: KindNone}
+	typeBool    = &Type{Kind: KindBool}
+	typeInt     = &Type{Kind: KindInt}
+	typeFloat   = &Type{Kind: KindFloat}
+	typeComplex = &Type{Kind: KindComplex}
+	typeStr     = &Type{Kind: KindStr}
+	typeList    = &Type{Kind: KindList}
+	typeSet     = &Type{Kind: KindSet}
+	typeTuple   = &Type{Kind: KindTuple}
+	typeDict    = &Type{Kind: KindDict}
+	typeRange   = &Type{Kind: KindRange}
+	typeIter    = &Type{Kind: KindIter}
+)
+
+// a typed list ([]elem in Go)
+func listOf(elem *Type) *Type {
+	if !elem.Known() {
+		return typeList
+	}
+
+	return &Type{Kind: KindList, Elem: elem}
+}
+
+// the type of **kwargs: a runtime.Dict, or a map[string]T if the type of the values is known
+func kwargsOf(elem *Type) *Type {
+	if !elem.Known() {
+		return &Type{Kind: KindDict, Key: typeStr}
 	}
-	if string(name.Id) != "__name__" {
+
+	return &Type{Kind: KindDict, Key: typeStr, Elem: elem}
+}
+
+// values of the Go type can be compared with == (as python does)
+func (t *Type) comparable() bool {
+	switch {
+	case t == nil:
 		return false
+
+	case t.Kind == KindInt:
+		return !bigInts
+
+	case t.Kind == KindBool, t.Kind == KindFloat, t.Kind == KindComplex, t.Kind == KindStr, t.Kind == KindObject:
+		return true
 	}
 
-	str, ok := comp.Comparators[0].(*ast.Str)
-	if !ok {
-		return false
+	return false
+}
+
+// values of the Go type can be compared with < (they satisfy cmp.Ordered)
+func (t *Type) orderable() bool {
+	return t != nil && (t.Kind == KindInt && !bigInts || t.Kind == KindFloat || t.Kind == KindStr)
+}
+
+// the type is a python dict translated to a runtime.OrderedDict
+func (t *Type) ordered() bool {
+	return t != nil && t.Kind == KindDict && t.Key == nil
+}
+
+func setOf(elem *Type) *Type {
+	if !elem.Known() {
+		return typeSet
 	}
-	if string(str.S) != "__main__" {
-		return false
+
+	return &Type{Kind: KindSet, Elem: elem}
+}
+
+// a typed dictionary (map[key]elem in Go)
+func dictOf(key, elem *Type) *Type {
+	if !key.Known() || !elem.Known() {
+		return typeDict
 	}
 
-	return true
+	return &Type{Kind: KindDict, Key: key, Elem: elem}
 }
 
-func exprIds(expr ast.Expr) (ids []ast.Identifier) {
-	if tuple, ok := expr.(*ast.Tuple); ok {
-		for _, x := range tuple.Elts {
-			ids = append(ids, x.(*ast.Name).Id)
-		}
-	} else {
-		ids = append(ids, expr.(*ast.Name).Id)
+// an iterator over elements of type elem (*runtime.Generator[elem] in Go)
+func iterOf(elem *Type) *Type {
+	if !elem.Known() {
+		return typeIter
 	}
 
-	return
+	return &Type{Kind: KindIter, Elem: elem}
 }
 
-func (s *Scope) gomprehension(c ast.Comprehension) (*jen.Statement, *jen.Statement) {
-	iter, _ := s.goFor(c.Target, c.Iter)
-	cond := iter
-	if len(c.Ifs) > 0 {
-		ccond := s.goExpr(c.Ifs[0])
-		for _, c := range c.Ifs[1:] {
-			ccond.Add(jen.Op("&&"))
-			ccond.Add(s.goExpr(c))
+// an instance of class (a pointer to the struct in Go)
+func objectOf(class string) *Type {
+	return &Type{Kind: KindObject, Class: class}
+}
+
+// a function with the given parameters and result (None for a function that doesn't return a value)
+func funcOf(params []*Type, result *Type) *Type {
+	return &Type{Kind: KindFunc, Params: params, Elem: result}
+}
+
+// the type is known (and it's not None, that can only be stored in an Any)
+func (t *Type) Known() bool {
+	return t != nil && t.Kind != KindAny && t.Kind != KindNone
+}
+
+// the type is a number (bool is a number in python)
+func (t *Type) Numeric() bool {
+	return t != nil && (t.Kind == KindBool || t.Kind == KindInt || t.Kind == KindFloat || t.Kind == KindComplex)
+}
+
+func (t *Type) Equal(o *Type) bool {
+	if t == nil || o == nil {
+		return t == o
+	}
+
+	if len(t.Params) != len(o.Params) {
+		return false
+	}
+
+	for i, p := range t.Params {
+		if !p.Equal(o.Params[i]) {
+			return false
 		}
-		cond = jen.If(ccond)
-		iter.Block(cond)
 	}
 
-	return iter, cond
+	return t.Kind == o.Kind && t.Elem.Equal(o.Elem) && t.Key.Equal(o.Key) && t.Class == o.Class && t.Variadic == o.Variadic
 }
 
-// print k=v either for function definitions (def=true) or for function call (def=false)
-func (s *Scope) goKvals(kk []*ast.Keyword, def bool) *jen.Statement {
-	return jen.ListFunc(func(g *jen.Group) {
-		if def {
-			for _, k := range kk {
-				g.Add(goId(k.Arg).Commentf("/*=%v*/", s.goExpr(k.Value).GoString()))
-			}
-		} else {
-			for _, k := range kk {
-				g.Commentf("/*%v=*/", string(k.Arg)).Add(s.goExpr(k.Value))
+// the Go type
+func (t *Type) Go() *jen.Statement {
+	if t == nil {
+		return goAny.Clone()
+	}
+
+	switch t.Kind {
+	case KindBool:
+		return jen.Bool()
+
+	case KindInt:
+		if bigInts {
+			return goInt.Clone()
+		}
+		return jen.Int()
+
+	case KindFloat:
+		return jen.Float64()
+
+	case KindComplex:
+		return jen.Complex128()
+
+	case KindStr:
+		return jen.String()
+
+	case KindList:
+		if t.Elem.Known() {
+			return jen.Index().Add(t.Elem.Go())
+		}
+		return goList.Clone()
+
+	case KindTuple:
+		return goTuple.Clone()
+
+	case KindDict:
+		switch {
+		case t.Key.Known() && t.Elem.Known():
+			return jen.Map(t.Key.Go()).Add(t.Elem.Go())
+
+		case t.Key.Known():
+			return goDict.Clone()
+		}
+		return goOrderedDict.Clone()
+
+	case KindSet:
+		if t.Elem.Known() {
+			return goSetOf.Clone().Index(t.Elem.Go())
+		}
+		return goSet.Clone()
+
+	case KindRange:
+		return goRange.Clone()
+
+	case KindIter:
+		return jen.Op("*").Add(goGenerator.Clone()).Index(t.elemType().Go())
+
+	case KindObject:
+		return jen.Op("*").Id(t.Class)
+
+	case KindFunc:
+		fn := jen.Func().ParamsFunc(func(g *jen.Group) {
+			for i, p := range t.Params {
+				if t.Variadic && i == len(t.Params)-1 {
+					g.Op("...").Add(p.Go())
+				} else {
+					g.Add(p.Go())
+				}
 			}
+		})
+		if t.Elem == nil || t.Elem.Kind != KindNone {
+			fn.Add(t.Elem.Go())
 		}
-	})
+		return fn
+	}
+
+	return goAny.Clone()
 }
 
-func (s *Scope) goExpr(expr interface{}) *jen.Statement {
-	if verbose {
-		log.Printf("XXX %T %#v\n\n", expr, expr)
+// the common type of a and b (Any if they are different)
+func unify(a, b *Type) *Type {
+	if a.Equal(b) {
+		return a
 	}
 
-	switch v := expr.(type) {
-	case []ast.Expr:
-		return s.goExprList(v)
+	return typeAny
+}
 
-	case []*ast.Keyword:
-		return jen.ListFunc(func(g *jen.Group) {
-			for _, k := range v {
-				g.Add(goId(k.Arg).Commentf("/*=%v*/", s.goExpr(k.Value).GoString()))
-			}
-		})
+// the type of the elements of a collection (when iterating over it)
+func (t *Type) elemType() *Type {
+	switch {
+	case t == nil:
+		return typeAny
+
+	case t.Kind == KindStr:
+		return typeStr
+
+	case t.Kind == KindRange:
+		return typeInt
+
+	case (t.Kind == KindList || t.Kind == KindSet || t.Kind == KindIter) && t.Elem != nil:
+		return t.Elem
+
+	case t.Kind == KindDict && t.Key != nil:
+		return t.Key
+	}
+
+	return typeAny
+}
 
+// the type of a literal value (Any if not a literal)
+func literalType(value ast.Expr) *Type {
+	switch t := value.(type) {
 	case *ast.Tuple:
-		return s.goInitialized(goTuple, v.Elts)
+		return typeTuple
 
 	case *ast.List:
-		return s.goInitialized(goList, v.Elts)
+		return typeList
 
 	case *ast.Dict:
-		return jen.Parens(goDict.Clone().Values(jen.DictFunc(func(d jen.Dict) {
-			for i, k := range v.Keys {
-				d[s.goExpr(k)] = s.goExpr(v.Values[i])
-			}
-		})))
+		return typeDict
+
+	case *ast.Str:
+		return typeStr
 
 	case *ast.Num:
-		switch n := v.N.(type) {
-		case py.Int:
-			return jen.Lit(int(n))
+		switch t.N.(type) {
+		case py.Int, *py.BigInt:
+			return typeInt
 
 		case py.Float:
-			return jen.Lit(float64(n))
+			return typeFloat
 
 		case py.Complex:
-			return jen.Lit(complex128(n))
+			return typeComplex
+		}
 
-		default:
-			log.Printf("number %v at %v:%v", v.N, v.GetLineno(), v.GetColOffset())
-			panic("invalid number")
+	case *ast.NameConstant:
+		switch t.Value {
+		case py.True, py.False:
+			return typeBool
+
+		case py.None:
+			return typeNone
 		}
+	}
 
-	case ast.Identifier:
-		return goId(v)
+	return typeAny
+}
+
+// the type described by an annotation (Any if it's not a type we know)
+func annotationType(ann ast.Expr) *Type {
+	switch t := ann.(type) {
+	case *ast.Name:
+		switch string(t.Id) {
+		case "int":
+			return typeInt
+		case "float":
+			return typeFloat
+		case "complex":
+			return typeComplex
+		case "str":
+			return typeStr
+		case "bool":
+			return typeBool
+		case "list", "List":
+			return typeList
+		case "tuple", "Tuple":
+			return typeTuple
+		case "dict", "Dict":
+			return typeDict
+		case "set", "Set", "frozenset", "FrozenSet":
+			return typeSet
+		}
 
 	case *ast.NameConstant:
-		switch v.Value {
-		case py.None:
-			return jen.Nil()
+		if t.Value == py.None {
+			return typeNone
+		}
 
-		case py.True:
-			return jen.True()
+	case *ast.Subscript: // List[int], Dict[str, int]
+		name, ok := t.Value.(*ast.Name)
+		index, iok := t.Slice.(*ast.Index)
+		if !ok || !iok {
+			break
+		}
 
-		case py.False:
-			return jen.False()
+		switch string(name.Id) {
+		case "list", "List":
+			return listOf(annotationType(index.Value))
 
-		default:
-			s, _ := py.Str(v.Value)
-			return jen.Lit(string(s.(py.String)))
+		case "set", "Set", "frozenset", "FrozenSet":
+			return setOf(annotationType(index.Value))
+
+		case "dict", "Dict":
+			if kv, ok := index.Value.(*ast.Tuple); ok && len(kv.Elts) == 2 {
+				return dictOf(annotationType(kv.Elts[0]), annotationType(kv.Elts[1]))
+			}
+
+		case "Callable": // Callable[[int, str], bool]
+			pr, ok := index.Value.(*ast.Tuple)
+			if !ok || len(pr.Elts) != 2 {
+				break
+			}
+
+			if params, ok := pr.Elts[0].(*ast.List); ok {
+				types := make([]*Type, len(params.Elts))
+				for i, p := range params.Elts {
+					types[i] = annotationType(p)
+				}
+				return funcOf(types, annotationType(pr.Elts[1]))
+			}
 		}
+	}
 
-	case *ast.Str:
-		return jen.Lit(string(v.S))
+	return typeAny
+}
 
-	case *ast.UnaryOp:
-		if v.Op == ast.Invert {
-			return jen.Op("-").Parens(s.goExpr(v.Operand).Op("+").Lit(1))
-		} else {
-			return s.goUnary(v.Op).Add(s.goExpr(v.Operand))
+// the type of the variable name, if known
+func (s *Scope) varType(name string) *Type {
+	fn := s.function()
+
+	for curr := s; curr != nil; curr = curr.prev {
+		if t, ok := curr.vars[name]; ok {
+			if t == nil {
+				return typeAny
+			}
+			return t
 		}
 
-	case *ast.BoolOp:
-		stmt := s.goExpr(v.Values[0])
-		for _, x := range v.Values[1:] {
-			stmt.Add(s.goBoolOp(v.Op))
-			stmt.Add(s.goExpr(x))
+		if curr == fn && fn.names.locals[name] && !fn.names.globals[name] && !fn.names.nonlocals[name] {
+			return typeAny
+		}
+	}
+
+	return typeAny
+}
+
+// the type of the numeric result of a binary operation between l and r
+func numericType(op ast.OperatorNumber, l, r *Type) *Type {
+	k := l.Kind
+	if r.Kind > k {
+		k = r.Kind
+	}
+	if k == KindBool {
+		k = KindInt
+	}
+
+	switch op {
+	case ast.Div:
+		if k == KindInt {
+			k = KindFloat
 		}
-		return stmt
+
+	case ast.LShift, ast.RShift, ast.BitAnd, ast.BitOr, ast.BitXor:
+		if k != KindInt {
+			return typeAny
+		}
+	}
+
+	return &Type{Kind: k}
+}
+
+// the type of the values returned by calling a builtin function or method
+var builtinTypes = map[string]*Type{
+	"len":        typeInt,
+	"int":        typeInt,
+	"ord":        typeInt,
+	"hash":       typeInt,
+	"float":      typeFloat,
+	"complex":    typeComplex,
+	"str":        typeStr,
+	"repr":       typeStr,
+	"chr":        typeStr,
+	"hex":        typeStr,
+	"oct":        typeStr,
+	"bin":        typeStr,
+	"bool":       typeBool,
+	"isinstance": typeBool,
+	"callable":   typeBool,
+	"any":        typeBool,
+	"all":        typeBool,
+	"list":       typeList,
+	"tuple":      typeTuple,
+	"dict":       typeDict,
+	"range":      typeRange,
+}
+
+var methodTypes = map[string]*Type{
+	"upper":      typeStr,
+	"lower":      typeStr,
+	"strip":      typeStr,
+	"lstrip":     typeStr,
+	"rstrip":     typeStr,
+	"join":       typeStr,
+	"replace":    typeStr,
+	"format":     typeStr,
+	"startswith": typeBool,
+	"endswith":   typeBool,
+	"isspace":    typeBool,
+	"isalpha":    typeBool,
+	"isdigit":    typeBool,
+	"isnumeric":  typeBool,
+	"isupper":    typeBool,
+	"islower":    typeBool,
+	"split":      listOf(typeStr),
+	"find":       typeInt,
+}
+
+// the type of an expression, as far as we can tell
+func (s *Scope) exprType(expr ast.Expr) *Type {
+	switch v := expr.(type) {
+	case *ast.Name:
+		return s.varType(string(v.Id))
 
 	case *ast.BinOp:
-		if v.Op == ast.Modulo { // %
-			if _, ok := v.Left.(*ast.Str); ok { // this is really a formatting operation
-				printfunc := jen.Qual("fmt", "Sprintf")
-				printfmt := s.goExpr(v.Left)
-				params := s.goExpr(v.Right)
-				if tuple, ok := v.Right.(*ast.Tuple); ok {
-					params = s.goExprList(tuple.Elts)
-				}
-				return printfunc.Params(printfmt, params)
+		l, r := s.exprType(v.Left), s.exprType(v.Right)
+
+		switch {
+		case l.Kind == KindStr && v.Op == ast.Modulo:
+			return typeStr
+
+		case l.Kind == KindStr && r.Kind == KindStr && v.Op == ast.Add:
+			return typeStr
+
+		case l.Kind == KindSet && r.Kind == KindSet && setOps[v.Op] != "":
+			return unify(l, r)
+
+		case l.ordered() && v.Op == ast.BitOr:
+			return typeDict
+
+		case l.Kind == KindList && r.Kind == KindList && v.Op == ast.Add:
+			return unify(l, r)
+
+		case v.Op == ast.Mult && (l.Kind == KindList && r.Kind == KindInt):
+			return l
+
+		case v.Op == ast.Mult && (l.Kind == KindInt && r.Kind == KindList):
+			return r
+
+		case v.Op == ast.Mult && (l.Kind == KindStr && r.Kind == KindInt || l.Kind == KindInt && r.Kind == KindStr):
+			return typeStr
+
+		case v.Op == ast.Pow && numericType(v.Op, l, r).Kind == KindInt:
+			base, bok := intConst(v.Left)
+			exp, eok := intConst(v.Right)
+
+			switch {
+			case eok && exp < 0:
+				return typeFloat
+			case bok && eok && powOverflows(base, exp):
+				return typeAny // a big.Int
 			}
+			return typeInt
+
+		case l.Numeric() && r.Numeric():
+			return numericType(v.Op, l, r)
 		}
 
-		if v.Op == ast.Pow { // **
-			return jen.Qual("math", "Pow").Params(s.goExpr(v.Left), s.goExpr(v.Right))
+	case *ast.UnaryOp:
+		if v.Op == ast.Not {
+			return typeBool
 		}
 
-		return s.goExpr(v.Left).Add(s.goOp(v.Op)).Add(s.goExpr(v.Right))
+		if t := s.exprType(v.Operand); t.Numeric() {
+			if t.Kind == KindBool {
+				return typeInt
+			}
+			return t
+		}
 
 	case *ast.Compare:
-		stmt := jen.Null()
+		return typeBool
 
-		left := s.goExpr(v.Left)
-		right := (*jen.Statement)(nil)
+	case *ast.BoolOp:
+		t := s.exprType(v.Values[0])
+		for _, x := range v.Values[1:] {
+			t = unify(t, s.exprType(x))
+		}
+		return t
 
-		for i, op := range v.Ops {
-			if right != nil {
-				stmt.Op("&&")
-				left = right.Clone()
+	case *ast.IfExp:
+		return unify(s.exprType(v.Body), s.exprType(v.Orelse))
+
+	case *ast.Call:
+		switch f := v.Func.(type) {
+		case *ast.Lambda:
+			return s.lambdaScope(f, s.argTypes(v.Args)).exprType(f.Body)
+
+		case *ast.Name:
+			if t := s.varType(string(f.Id)); t.Kind == KindFunc { // a lambda or a Callable parameter
+				if t.Elem == nil {
+					return typeAny
+				}
+				return t.Elem
 			}
 
-			right = s.goExpr(v.Comparators[i])
+			if t := s.builtinType(v); t != nil {
+				return t
+			}
 
-			if op == ast.In {
-				stmt.Add(goContains.Clone().Call(right, left))
-			} else if op == ast.NotIn {
-				stmt.Op("!").Add(goContains.Clone().Call(right, left))
-			} else {
-				stmt.Add(left)
-				stmt.Add(s.goCmpOp(op))
-				stmt.Add(right)
+			if t, ok := builtinTypes[string(f.Id)]; ok {
+				return t
 			}
-		}
 
-		return stmt
+			if s.classes[string(f.Id)] {
+				return objectOf(string(f.Id))
+			}
 
-	case *ast.Name:
-		return goId(v.Id)
+			if name := string(f.Id); (name == "set" || name == "frozenset") && len(v.Args) <= 1 {
+				if len(v.Args) == 1 {
+					if t := s.exprType(v.Args[0]); t.Kind == KindList || t.Kind == KindSet {
+						return setOf(t.elemType())
+					}
+				}
+				return typeSet
+			}
 
-	case *ast.Attribute:
-		x, b, a := strAttribute(v)
-		a = rename(a)
+		case *ast.Attribute:
+			if t, ok := methodTypes[string(f.Attr)]; ok && s.exprType(f.Value).Kind == KindStr {
+				return t
+			}
 
-		if x != nil {
-			return s.goExpr(x).Dot(a)
-		}
+			if t, ok := dictMethodTypes[string(f.Attr)]; ok && s.exprType(f.Value).ordered() {
+				return t
+			}
 
-		switch {
-		case b == "re" && a == "compile":
-			return jen.Qual("regexp", "MustCompile")
+			if t := s.exprType(f.Value); t.Kind == KindDict && !t.ordered() {
+				switch string(f.Attr) {
+				case "get", "setdefault", "pop":
+					if t.Elem == nil { // runtime.Dict
+						return typeAny
+					}
+					if string(f.Attr) == "get" && len(v.Args) == 1 { // the value or None
+						return typeAny
+					}
+					return t.Elem
 
-		case b == "re" && a == "match":
-			return jen.Qual("regexp", "MatchString")
+				case "keys":
+					return listOf(t.Key)
 
-		case b == "sys" && a == "argv":
-			return jen.Qual("os", "Args")
+				case "values":
+					return listOf(t.Elem)
 
-		case b == "sys" && a == "stdin":
-			return jen.Qual("os", "Stdin")
+				case "copy":
+					return t
 
-		case b == "sys" && a == "stdout":
-			return jen.Qual("os", "Stdout")
+				case "popitem":
+					return typeTuple
 
-		case b == "sys" && a == "stderr":
-			return jen.Qual("os", "Stderr")
+				case "items":
+					return typeList
+				}
+			}
 
-		case b == "sys.stdin":
-			return jen.Qual("os", "Stdin").Dot(a)
+			if t := s.exprType(f.Value); t.Kind == KindList {
+				switch string(f.Attr) {
+				case "pop":
+					return t.elemType()
 
-		case b == "sys.stdout":
-			return jen.Qual("os", "Stdout").Dot(a)
+				case "index", "count":
+					return typeInt
 
-		case b == "sys.stderr":
-			return jen.Qual("os", "Stderr").Dot(a)
-		}
+				case "copy":
+					return t
+				}
+			}
 
-		if imp, ok := s.imports[b]; ok {
-			return jen.Qual(imp, a)
+			if t := s.exprType(f.Value); t.Kind == KindSet {
+				if r, ok := setMethodTypes[string(f.Attr)]; ok {
+					if r == nil { // same as the receiver
+						return t
+					}
+					return r
+				}
+				if string(f.Attr) == "pop" {
+					return t.elemType()
+				}
+			}
 		}
 
-		return jen.Id(b).Dot(a)
+		if sig := s.callSignature(v); sig != nil && len(sig.results) > 0 {
+			return typeTuple
+		} else if sig != nil && sig.returns != nil {
+			return sig.returns
+		}
 
 	case *ast.Subscript:
-		return s.goSlice(v.Value, v.Slice)
+		t := s.exprType(v.Value)
 
-	case *ast.Call:
-		return s.goCall(v)
+		if _, ok := v.Slice.(*ast.Slice); ok {
+			if t.Kind == KindStr || t.Kind == KindList || t.Kind == KindRange {
+				return t
+			}
+			break
+		}
 
-	case *ast.Lambda:
-		args, _ := s.goFunctionArguments(v.Args, false)
-		return jen.Func().Params(args).Block(s.goExpr(v.Body)).Call()
+		switch {
+		case t.Kind == KindStr:
+			return typeStr
 
-	case *ast.IfExp:
-		return jen.Func().Params().Block(
-			jen.If(s.goExpr(v.Test)).
-				Block(jen.Return(s.goExpr(v.Body))).
-				Else().
-				Block(jen.Return(s.goExpr(v.Orelse)))).Call()
+		case t.Kind == KindRange:
+			return typeInt
 
-	case *ast.ListComp:
-		outer, inner := s.gomprehension(v.Generators[0])
-		for _, g := range v.Generators[1:] {
-			outer1, inner1 := s.gomprehension(g)
-			inner.Add(jen.Block(outer1))
-			inner = inner1
+		case (t.Kind == KindList || t.Kind == KindDict) && t.Elem != nil:
+			return t.Elem
 		}
-		inner.Add(jen.Block(jen.Id("lc").Op("=").Append(jen.Id("lc"), s.goExpr(v.Elt))))
-		return jen.Func().Params().Params(jen.Id("lc").Add(goList)).Block(outer, jen.Return(jen.Id("lc"))).Call()
 
-	case *ast.DictComp:
-		outer, inner := s.gomprehension(v.Generators[0])
-		for _, g := range v.Generators[1:] {
-			outer1, inner1 := s.gomprehension(g)
-			inner.Add(jen.Block(outer1))
-			inner = inner1
-		}
-		inner.Add(jen.Block(jen.Id("mm").Index(s.goExpr(v.Key)).Op("=").Add(s.goExpr(v.Value))))
-		return jen.Func().Params().Params(jen.Id("mm").Add(goDict)).Block(
-			jen.Id("mm").Op("=").Add(goDict).Values(),
-			outer,
-			jen.Return()).Call()
+	case *ast.ListComp:
+		return listOf(s.comprehensionType(v.Generators, v.Elt))
 
 	case *ast.GeneratorExp:
-		outer, inner := s.gomprehension(v.Generators[0])
-		for _, g := range v.Generators[1:] {
-			outer1, inner1 := s.gomprehension(g)
-			inner.Add(jen.Block(outer1))
-			inner = inner1
-		}
-		inner.Add(jen.Block(jen.Id("c").Op("<-").Add(s.goExpr(v.Elt))))
-		return jen.Func().Params().Params(jen.Id("c").Chan().Add(goAny)).Block(
-			jen.Id("c").Op("=").Make(jen.Chan().Add(goAny)),
-			jen.Go().Func().Params().Block(outer, jen.Close(jen.Id("c"))).Call(),
-			jen.Return(),
-		).Call()
+		return iterOf(s.comprehensionType(v.Generators, v.Elt))
+
+	case *ast.DictComp:
+		return dictOf(s.comprehensionType(v.Generators, v.Key), s.comprehensionType(v.Generators, v.Value))
+
+	case *ast.Set:
+		return setOf(s.valuesType(v.Elts))
+
+	case *ast.SetComp:
+		return setOf(s.comprehensionType(v.Generators, v.Elt))
+
+	case *ast.Lambda:
+		return s.lambdaType(v)
+
+	default:
+		return literalType(expr)
 	}
 
-	return unknown("EXPR", expr)
+	return typeAny
 }
 
-func goId(id ast.Identifier) *jen.Statement {
-	return jen.Id(rename(string(id)))
+func (s *Scope) goAssign(assign *ast.Assign) (*jen.Statement, *jen.Statement, *jen.Statement) {
+	goType := literalType(assign.Value).Go()
+
+	if len(assign.Targets) == 1 && (isTuple(assign.Targets[0]) || isList(assign.Targets[0])) {
+		return s.goExprOrList(assign.Targets[0]), s.goExprOrList(assign.Value), goType
+	}
+
+	return s.goExpr(assign.Targets), s.goExpr(assign.Value), goType
 }
 
-func (s *Scope) goFunctionArguments(args *ast.Arguments, skipReceiver bool) (*jen.Statement, *ast.Arg) {
-	var recv *ast.Arg
+// an assignment to a target pattern, flattened to simple targets (names, attributes and items):
+// the statements that unpack the values into temporary tuples, and the value (and type) of each target.
+// The values can also be a single call to a function that returns a value for each target.
+type unpacking struct {
+	pre     []jen.Code
+	targets []ast.Expr
+	values  []jen.Code
+	types   []*Type
+}
 
-	if args == nil {
-		return jen.Null(), recv
+func (u *unpacking) add(target ast.Expr, value jen.Code, t *Type) {
+	u.targets = append(u.targets, target)
+	u.values = append(u.values, value)
+	u.types = append(u.types, t)
+}
+
+// the statements in pre, each followed by a new line
+func (u *unpacking) goPre() *jen.Statement {
+	stmt := jen.Null()
+	for _, p := range u.pre {
+		stmt.Add(p).Line()
 	}
 
-	var params []jen.Code
+	return stmt
+}
 
-	aargs := args.Args
-	if skipReceiver && len(aargs) > 0 {
-		recv, aargs = aargs[0], aargs[1:]
+// the elements of a tuple or list target pattern
+func patternElts(target ast.Expr) ([]ast.Expr, bool) {
+	switch t := target.(type) {
+	case *ast.Tuple:
+		return t.Elts, true
+
+	case *ast.List:
+		return t.Elts, true
 	}
 
-	for _, arg := range aargs {
-		s.addName(arg.Arg)
+	return nil, false
+}
 
-		p := goId(arg.Arg)
-		if arg.Annotation != nil {
-			p.Add(s.goExpr(arg.Annotation))
-		} else {
-			p.Add(goAny)
+// check if the elements of a target pattern are all simple targets (no nested or starred patterns)
+func isFlat(elts []ast.Expr) bool {
+	for _, x := range elts {
+		switch x.(type) {
+		case *ast.Tuple, *ast.List, *ast.Starred:
+			return false
 		}
-
-		params = append(params, p)
 	}
 
-	for i, arg := range args.Kwonlyargs {
-		s.addName(arg.Arg)
+	return true
+}
 
-		p := goId(arg.Arg)
-		if arg.Annotation != nil {
-			p.Add(s.goExpr(arg.Annotation))
-		} else {
-			p.Add(goAny)
+// the index of the starred element of a target pattern (-1 if there isn't one)
+func starredIndex(elts []ast.Expr) int {
+	for i, x := range elts {
+		if _, ok := x.(*ast.Starred); ok {
+			return i
 		}
-
-		p.Commentf("/*=%v*/", s.goExpr(args.KwDefaults[i]).GoString())
-		params = append(params, p)
 	}
 
-	if args.Vararg != nil {
-		s.addName(args.Vararg.Arg)
+	return -1
+}
 
-		p := goId(args.Vararg.Arg).Comment("/*...*/")
-		if args.Vararg.Annotation != nil {
-			p.Add(s.goExpr(args.Vararg.Annotation))
-		} else {
-			p.Add(goAny)
+// add to u the assignment of value to target. If value is nil the value is goValue, of type t.
+//
+// A tuple (or list) of values assigned to a pattern of the same length is assigned element by element,
+// any other value is unpacked into a temporary tuple (with runtime.Unpack), and the pattern elements
+// are assigned from its elements.
+func (s *Scope) unpackTarget(u *unpacking, target, value ast.Expr, goValue jen.Code, t *Type) {
+	elts, ok := patternElts(target)
+	if !ok {
+		if value != nil {
+			goValue, t = s.goExpr(value), s.exprType(value)
 		}
+		u.add(target, goValue, t)
+		return
+	}
 
-		params = append(params, p)
+	if values, ok := patternElts(value); ok && len(values) == len(elts) && starredIndex(elts) < 0 {
+		for i, x := range elts {
+			s.unpackTarget(u, x, values[i], nil, nil)
+		}
+		return
 	}
 
-	if args.Kwarg != nil {
-		s.addName(args.Kwarg.Arg)
+	if types := s.callResults(value); len(types) == len(elts) && starredIndex(elts) < 0 {
+		// the results of the call are assigned to temporary variables
+		results := make([]jen.Code, len(types))
+		for i := range results {
+			results[i] = jen.Id(s.generatedName("_v"))
+		}
 
-		p := goId(args.Kwarg.Arg).Comment("/*...*/")
-		if args.Vararg.Annotation != nil {
-			p.Add(s.goExpr(args.Kwarg.Annotation))
-		} else {
-			p.Add(goAny)
+		u.pre = append(u.pre, s.goDefine(results, s.goCall(value.(*ast.Call))))
+		for i, x := range elts {
+			s.unpackTarget(u, x, nil, results[i], types[i])
 		}
+		return
+	}
 
-		params = append(params, p)
+	if value != nil {
+		goValue = s.goExpr(value)
 	}
 
-	// XXX: what is arg.Defaults ?
+	unpack := jen.Qual(goRuntime, "Unpack").Call(goValue, jen.Lit(len(elts)))
+	if k := starredIndex(elts); k >= 0 {
+		unpack = jen.Qual(goRuntime, "UnpackStarred").Call(goValue, jen.Lit(k), jen.Lit(len(elts)-k-1))
+	}
 
-	return jen.List(params...), recv
+	tuple := s.generatedName("_t")
+	u.pre = append(u.pre, s.goDefine([]jen.Code{jen.Id(tuple)}, unpack))
+	s.unpackElements(u, elts, tuple)
 }
 
-func strAttribute(attr *ast.Attribute) (ast.Expr, string, string) {
-	var expr ast.Expr
-	var base string
-
-	switch v := attr.Value.(type) {
-	case *ast.Attribute:
-		_, b, a := strAttribute(v)
-		base = b + "." + a
-		expr = nil
-
-	case *ast.Name:
-		base = string(v.Id)
-		expr = nil
+// add to u the assignment of the elements of tuple (the name of a Go variable) to the pattern elements elts.
+// The tuple has an element for each pattern element, a list for the starred one.
+func (s *Scope) unpackElements(u *unpacking, elts []ast.Expr, tuple string) {
+	for i, x := range elts {
+		item := jen.Id(tuple).Index(jen.Lit(i))
+		if st, ok := x.(*ast.Starred); ok {
+			u.add(st.Value, item.Assert(goList.Clone()), typeList)
+		} else {
+			s.unpackTarget(u, x, nil, item, typeAny)
+		}
+	}
+}
 
-	default:
-		expr = attr.Value
+// the types of the values returned by a call to a function returning multiple Go results
+// (nil if value is not such a call)
+func (s *Scope) callResults(value ast.Expr) []*Type {
+	call, ok := value.(*ast.Call)
+	if !ok {
+		return nil
 	}
 
-	return expr, base, string(attr.Attr)
-}
+	sig := s.callSignature(call)
+	if sig == nil || len(sig.results) == 0 {
+		return nil
+	}
 
-func (s *Scope) goCallParams(params ...ast.Expr) *jen.Statement {
-	return jen.ParamsFunc(func(g *jen.Group) {
-		for _, p := range params {
-			g.Add(s.goExpr(p))
+	types := make([]*Type, len(sig.results))
+	for i, t := range sig.results {
+		if types[i] = t; t == nil { // the function hasn't been parsed yet
+			types[i] = typeAny
 		}
-	})
-}
+	}
 
-func (s *Scope) goCall(call *ast.Call) *jen.Statement {
-	cfunc := s.goExpr(call.Func)
+	return types
+}
 
-	switch ff := call.Func.(type) {
+// add the names bound by the target pattern, as Any (or lists, for the starred targets)
+func (s *Scope) addPatternNames(target ast.Expr) {
+	switch t := target.(type) {
 	case *ast.Name:
-		switch string(ff.Id) {
-		case "print":
-			cfunc = jen.Qual("fmt", "Println") // check for print parameters, could be fmt.Print, fmt.Fprint, etc.
+		s.addName(t.Id, typeAny)
 
-		case "open":
-			cfunc = jen.Qual("os", "Open") // could also be os.OpenFile
+	case *ast.Starred:
+		for _, name := range targetNames(t.Value) {
+			s.addName(ast.Identifier(name), typeList)
+		}
 
-		case "isinstance": // isinstance(obj, type)
-			if len(call.Args) == 2 {
-				obj := s.goExpr(call.Args[0])
-				otype := s.goExpr(call.Args[1])
-				comment := jen.Commentf("isinstance(%v, %v)", obj.GoString(), otype.GoString())
-				if attr, ok := call.Args[1].(*ast.Attribute); ok {
-					otype = jen.Commentf("/*%v*/", s.goExpr(attr.Value).GoString()).Add(s.goExpr(attr.Attr))
-				}
-				return jen.Func().Params().Bool().Block(
-					comment,
-					jen.List(jen.Op("_"), jen.Id("ok")).Op(":=").Add(obj).Assert(otype),
-					jen.Return(jen.Id("ok")),
-				).Call()
-			}
+	case *ast.Tuple, *ast.List:
+		elts, _ := patternElts(t)
+		for _, x := range elts {
+			s.addPatternNames(x)
+		}
+	}
+}
 
-		case "type":
-			cfunc = jen.Qual("reflect", "Type")
+// assign the unpacked values to their targets, declaring the names that are not defined yet.
+//
+// The values are assigned in parallel (a, b = b, a), unless some targets are items of
+// ordered dicts, that are set after evaluating all the values.
+func (s *Scope) goUnpacked(u *unpacking) *jen.Statement {
+	stmt := u.goPre()
+
+	targets, values := u.targets, u.values
+	dictItems := false
+	for _, x := range targets {
+		if item, _ := s.dictItem(x); item != nil {
+			dictItems = true
 		}
+	}
 
-	case *ast.Attribute:
-		switch string(ff.Attr) {
-		case "read":
-			cfunc = s.goExpr(ff.Value).Dot("Read")
+	if dictItems && len(targets) == 1 { // d[k] = v
+		item, key := s.dictItem(targets[0])
+		return stmt.Add(s.goExpr(item).Dot("Set").Call(s.goExpr(key), values[0]))
+	}
 
-		case "write":
-			cfunc = s.goExpr(ff.Value).Dot("Write")
+	if dictItems {
+		temps := make([]jen.Code, len(targets))
+		for i := range temps {
+			temps[i] = jen.Id(s.generatedName("_v"))
+		}
+		stmt.Add(s.goDefine(temps, values...)).Line()
+		values = temps
+	}
 
-		case "close":
-			cfunc = s.goExpr(ff.Value).Dot("Close")
+	var names []string
+	var types []*Type
+	declared := map[string]bool{}
+	widened := false
 
-		case "items": // as in `for k, v in dict(a=1).items()`
-			return s.goExpr(ff.Value) // remove items
+	for i, x := range targets {
+		if name, ok := x.(*ast.Name); ok && !s.isDefined(string(name.Id)) && !declared[string(name.Id)] {
+			t := s.variableType(string(name.Id), u.types[i])
+			widened = widened || !t.Equal(u.types[i])
 
-		case "append":
-			if len(call.Args) == 1 {
-				return s.goExpr(ff.Value).Op("=").Id("append").
-					Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
-			}
+			names = append(names, string(name.Id))
+			types = append(types, t)
+			declared[string(name.Id)] = true
+		}
+	}
 
-		case "upper":
-			return jen.Qual("strings", "ToUpper").Call(s.goExpr(ff.Value))
+	if len(names) == len(targets) && !dictItems && !widened { // all new names: var a, b = x, y
+		for i, name := range names {
+			s.vars[name] = types[i]
+		}
+		return stmt.Var().Add(s.goExprList(targets)).Op("=").List(values...)
+	}
 
-		case "lower":
-			return jen.Qual("strings", "ToLower").Call(s.goExpr(ff.Value))
+	if len(names) > 0 {
+		stmt.Add(goVarDecls(names, types)).Line()
+		for i, name := range names {
+			s.vars[name] = types[i]
+		}
+	}
 
-		case "startswith":
-			if len(call.Args) == 1 {
-				return jen.Qual("strings", "HasPrefix").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
-			}
+	if !dictItems {
+		return stmt.Add(s.goExprList(targets)).Op("=").List(values...)
+	}
 
-		case "endswith":
-			if len(call.Args) == 1 {
-				return jen.Qual("strings", "HasSuffix").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
-			}
+	for i, x := range targets {
+		if i > 0 {
+			stmt.Line()
+		}
 
-		case "strip":
-			if len(call.Args) == 0 {
-				return jen.Qual("strings", "TrimSpace").Call(s.goExpr(ff.Value))
-			} else if len(call.Args) == 1 {
-				return jen.Qual("strings", "Trim").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
-			}
+		if item, key := s.dictItem(x); item != nil {
+			stmt.Add(s.goExpr(item).Dot("Set").Call(s.goExpr(key), values[i]))
+		} else {
+			stmt.Add(s.goExpr(x)).Op("=").Add(values[i])
+		}
+	}
 
-		case "lstrip":
-			if len(call.Args) == 0 {
-				return jen.Qual(goRuntime, "TrimLeft").Call(s.goExpr(ff.Value))
-			} else if len(call.Args) == 1 {
-				return jen.Qual("strings", "TrimLeft").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
-			}
+	return stmt
+}
 
-		case "rstrip":
-			if len(call.Args) == 0 {
-				return jen.Qual(goRuntime, "TrimRight").Call(s.goExpr(ff.Value))
-			} else if len(call.Args) == 1 {
-				return jen.Qual("strings", "TrimRight").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
-			}
+// the name of the temporary variable holding the value of expr, if it was evaluated before the statement
+func (s *Scope) tempName(expr ast.Expr) (string, bool) {
+	for curr := s; curr != nil; curr = curr.prev {
+		if name, ok := curr.temps[expr]; ok {
+			return name, true
+		}
+	}
 
-		case "split":
-			if len(call.Args) == 0 {
-				return jen.Qual(goRuntime, "Splits").Call(s.goExpr(ff.Value))
-			} else if len(call.Args) == 1 {
-				return jen.Qual("strings", "Split").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
-			} else if len(call.Args) == 2 {
-				return jen.Qual("strings", "SplitN").Call(s.goExpr(ff.Value),
-					s.goExpr(call.Args[0]),
-					s.goExpr(call.Args[1]).Op("+").Lit(1))
-			}
+	return "", false
+}
 
-		case "join":
-			if len(call.Args) == 1 {
-				return jen.Qual("strings", "Join").Call(s.goExpr(call.Args[0]), s.goExpr(ff.Value))
-			}
+// the expressions in exprs (the expressions of a statement, in evaluation order) that can be lowered
+// to statements before the statement without changing the evaluation order: the ones that are always
+// evaluated, and only after values without side effects (names, constants and other lowered expressions).
+//
+// The expressions that can be lowered are conditional expressions, list, set and dict comprehensions
+// and isinstance() calls.
+func hoistable(exprs ...ast.Expr) map[ast.Expr]bool {
+	found := make(map[ast.Expr]bool)
+	safe := true // nothing with side effects has been evaluated so far
+
+	var walk func(expr ast.Expr)
+	walk = func(expr ast.Expr) {
+		if !safe || expr == nil {
+			return
+		}
 
-		case "replace":
-			if len(call.Args) == 2 {
-				return jen.Qual("strings", "Replace").Call(s.goExpr(ff.Value),
-					s.goExpr(call.Args[0]),
-					s.goExpr(call.Args[1]),
-					jen.Lit(-1))
-			} else if len(call.Args) == 3 {
-				return jen.Qual("strings", "Replace").Call(s.goExpr(ff.Value),
-					s.goExpr(call.Args[0]),
-					s.goExpr(call.Args[1]),
-					s.goExpr(call.Args[2]))
-			}
+		switch v := expr.(type) {
+		case *ast.IfExp:
+			found[v] = true
 
-		case "count":
-			if len(call.Args) == 1 {
-				return jen.Qual("strings", "Count").Call(s.goExpr(ff.Value), s.goExpr(call.Args[0]))
-			}
+		case *ast.ListComp:
+			walk(v.Generators[0].Iter) // the only part evaluated before the comprehension
+			found[v] = true
+
+		case *ast.SetComp:
+			walk(v.Generators[0].Iter)
+			found[v] = true
 
-		case "isspace":
-			if len(call.Args) == 0 {
-				return jen.Qual(goRuntime, "IsSpace").Call(s.goExpr(ff.Value))
-			}
+		case *ast.DictComp:
+			walk(v.Generators[0].Iter)
+			found[v] = true
 
-		case "isalpha":
-			if len(call.Args) == 0 {
-				return jen.Qual(goRuntime, "IsAlpha").Call(s.goExpr(ff.Value))
+		case *ast.Call:
+			walk(v.Func)
+			for _, a := range v.Args {
+				walk(a)
+			}
+			for _, k := range v.Keywords {
+				walk(k.Value)
 			}
+			walk(v.Starargs)
+			walk(v.Kwargs)
 
-		case "isdigit", "isnumeric":
-			if len(call.Args) == 0 {
-				return jen.Qual(goRuntime, "IsDigit").Call(s.goExpr(ff.Value))
+			if isInstance(v) {
+				found[v] = true
+			} else {
+				safe = false // the call itself
 			}
 
-		case "isupper":
-			if len(call.Args) == 0 {
-				return jen.Qual(goRuntime, "IsUpper").Call(s.goExpr(ff.Value))
+		case *ast.BinOp:
+			walk(v.Left)
+			walk(v.Right)
+
+		case *ast.UnaryOp:
+			walk(v.Operand)
+
+		case *ast.BoolOp: // the values after the first one may not be evaluated
+			walk(v.Values[0])
+			safe = false
+
+		case *ast.Compare: // the comparisons after the first one may not be evaluated
+			walk(v.Left)
+			walk(v.Comparators[0])
+			safe = safe && len(v.Comparators) == 1
+
+		case *ast.Attribute:
+			walk(v.Value)
+
+		case *ast.Subscript:
+			walk(v.Value)
+			if index, ok := v.Slice.(*ast.Index); ok {
+				walk(index.Value)
+			} else {
+				safe = false
 			}
 
-		case "islower":
-			if len(call.Args) == 0 {
-				return jen.Qual(goRuntime, "IsLower").Call(s.goExpr(ff.Value))
+		case *ast.Tuple:
+			for _, x := range v.Elts {
+				walk(x)
 			}
 
-		case "reverse":
-			if len(call.Args) == 0 {
-				return jen.Qual(goRuntime, "Reverse").Call(s.goExpr(ff.Value))
+		case *ast.List:
+			for _, x := range v.Elts {
+				walk(x)
 			}
+
+		default:
+			safe = isSimple(expr)
 		}
+	}
 
-		if name, ok := ff.Value.(*ast.Name); ok {
-			switch {
-			case string(name.Id) == "sys" && string(ff.Attr) == "exit":
-				ret := jen.Lit(-1)
-				if len(call.Args) > 0 {
-					ret = s.goExpr(call.Args[0])
-				}
-				return jen.Qual("os", "Exit").Call(ret)
+	for _, expr := range exprs {
+		walk(expr)
+	}
 
-			case string(name.Id) == "time" && string(ff.Attr) == "sleep" && len(call.Args) == 1:
-				tt := jen.Qual("time", "Duration").Parens(
-					s.goExpr(call.Args[0]).Op("*").Float64().Parens(jen.Qual("time", "Second")))
-				return jen.Qual("time", "Sleep").Call(tt)
+	return found
+}
 
-			case string(name.Id) == "time" && string(ff.Attr) == "time" && len(call.Args) == 0:
-				return jen.Qual("time", "Now").Call()
-			}
+// isinstance(obj, type)
+func isInstance(call *ast.Call) bool {
+	name, ok := call.Func.(*ast.Name)
+	return ok && string(name.Id) == "isinstance" && len(call.Args) == 2 && len(call.Keywords) == 0
+}
+
+// translate a statement, with translate, letting the expression translators add statements before it
+// to evaluate the expressions in exprs (the expressions of the statement, in evaluation order) that can be
+// lowered (see hoistable and goLowered)
+func (s *Scope) withHoisting(translate func() *jen.Statement, exprs ...ast.Expr) *jen.Statement {
+	pre, stmt := s.hoisting(translate, exprs...)
+	if pre == nil {
+		return stmt
+	}
+
+	return pre.Add(stmt)
+}
+
+// the same as withHoisting, but the statements to add before the statement (nil if there are none)
+// are returned separately from the translation
+func (s *Scope) hoisting(translate func() *jen.Statement, exprs ...ast.Expr) (pre, stmt *jen.Statement) {
+	if s.Top() { // no statements at package level
+		return nil, translate()
+	}
+
+	prevPre, prevHoist := s.pre, s.hoist
+	s.pre, s.hoist = jen.Null(), hoistable(exprs...)
+
+	stmt = translate()
+	if len(*s.pre) > 0 {
+		pre = s.pre
+	}
+
+	s.pre, s.hoist = prevPre, prevHoist
+	return pre, stmt
+}
+
+// the scope of the statement being translated, if expr can be evaluated before it (nil otherwise)
+func (s *Scope) hoistScope(expr ast.Expr) *Scope {
+	for curr := s; curr != nil; curr = curr.prev {
+		if curr.hoist[expr] {
+			return curr
 		}
 	}
 
-	var args []jen.Code
+	return nil
+}
 
-	for _, arg := range call.Args {
-		args = append(args, s.goExpr(arg))
+// expr translated as a temporary variable, evaluated by statements added before the statement
+// being translated (nil if the statement doesn't allow it, see withHoisting)
+func (s *Scope) goLowered(expr ast.Expr) *jen.Statement {
+	if name, ok := s.tempName(expr); ok { // already lowered
+		return jen.Id(name)
 	}
 
-	if len(call.Keywords) > 0 {
-		args = append(args, s.goKvals(call.Keywords, false))
+	hs := s.hoistScope(expr)
+	if hs == nil {
+		return nil
 	}
 
-	if call.Starargs != nil {
-		args = append(args, s.goExpr(call.Starargs).Comment("/*...*/"))
+	name := s.generatedName("_v")
+	lowered := s.goLowering(expr, name) // this can lower subexpressions, before this one
+	hs.pre.Add(lowered).Line()
+
+	if hs.temps == nil {
+		hs.temps = make(map[ast.Expr]string)
 	}
+	hs.temps[expr] = name
+	return jen.Id(name)
+}
 
-	if call.Kwargs != nil {
-		args = append(args, s.goExpr(call.Kwargs).Comment("/*...*/"))
+// the statements that evaluate expr into the new variable name (a Go identifier),
+// for the expressions that can be lowered (nil for the others)
+func (s *Scope) goLowering(expr ast.Expr, name string) *jen.Statement {
+	t := s.exprType(expr)
+
+	switch v := expr.(type) {
+	case *ast.IfExp:
+		return jen.Var().Id(name).Add(t.Go()).Line().Add(s.goIfExpAssign(v, jen.Id(name)))
+
+	case *ast.ListComp:
+		decl := jen.Var().Id(name).Add(t.Go())
+		if size := s.goComprehensionSize(v.Generators); size != nil {
+			decl = jen.Id(name).Op(":=").Make(t.Go(), jen.Lit(0), size)
+		}
+
+		return decl.Line().Add(s.goComprehension(v.Generators, []ast.Expr{v.Elt}, func(cs *Scope) jen.Code {
+			return jen.Id(name).Op("=").Append(jen.Id(name), cs.goExpr(v.Elt))
+		}))
+
+	case *ast.SetComp:
+		return jen.Id(name).Op(":=").Add(t.Go()).Values().Line().Add(s.goComprehension(v.Generators, []ast.Expr{v.Elt}, func(cs *Scope) jen.Code {
+			return jen.Id(name).Dot("Add").Call(cs.goExpr(v.Elt))
+		}))
+
+	case *ast.DictComp:
+		elts := []ast.Expr{v.Key, v.Value}
+
+		if t.ordered() {
+			return jen.Id(name).Op(":=").Qual(goRuntime, "NewOrderedDict").Call().Line().Add(s.goComprehension(v.Generators, elts, func(cs *Scope) jen.Code {
+				return jen.Id(name).Dot("Set").Call(cs.goExpr(v.Key), cs.goExpr(v.Value))
+			}))
+		}
+
+		size := s.goComprehensionSize(v.Generators)
+		if size == nil {
+			size = jen.Null()
+		}
+
+		return jen.Id(name).Op(":=").Make(t.Go(), size).Line().Add(s.goComprehension(v.Generators, elts, func(cs *Scope) jen.Code {
+			return jen.Id(name).Index(cs.goExpr(v.Key)).Op("=").Add(cs.goExpr(v.Value))
+		}))
+
+	case *ast.Call:
+		if isInstance(v) {
+			obj, otype := s.goInstanceOf(v)
+			return jen.Commentf("isinstance(%v, %v)", obj.GoString(), otype.GoString()).Line().
+				List(jen.Op("_"), jen.Id(name)).Op(":=").Add(obj).Assert(otype)
+		}
 	}
 
-	return cfunc.Call(args...)
+	return nil
 }
 
-func (s *Scope) goFor(target, iter ast.Expr) (*jen.Statement, []ast.Expr) {
-	for _, id := range exprIds(target) {
-		s.addName(id)
+// the parts of isinstance(obj, type): the object and the type it is asserted to
+func (s *Scope) goInstanceOf(call *ast.Call) (obj, otype *jen.Statement) {
+	obj = s.goExpr(call.Args[0])
+	otype = s.goExpr(call.Args[1])
+	if attr, ok := call.Args[1].(*ast.Attribute); ok {
+		otype = jen.Commentf("/*%v*/", s.goExpr(attr.Value).GoString()).Add(s.goExpr(attr.Attr))
 	}
 
-	if c, ok := iter.(*ast.Call); ok { // check for "for x in range(n)"
-		//
-		// for x in range(y)
-		//
-		if n, ok := c.Func.(*ast.Name); ok && string(n.Id) == "range" {
-			if len(c.Args) < 1 || len(c.Args) > 3 {
-				log.Printf("at %v:%v", iter.GetLineno(), iter.GetColOffset())
-				panic("range expects 1 to 3 arguments")
-			}
+	return
+}
 
-			start := jen.Lit(0)
-			step := jen.Lit(1)
+// an expression that can't be lowered before its statement, evaluated by a function literal
+// that lowers it into the variable name and returns it
+func (s *Scope) goLoweringFunc(expr ast.Expr, name string) *jen.Statement {
+	return jen.Func().Params().Add(s.exprType(expr).Go()).Block(
+		s.goLowering(expr, name),
+		jen.Return(jen.Id(name))).Call()
+}
 
-			var stop jen.Code
+// the loops of a comprehension, with the body (translated in the scope of the comprehension) in the innermost one.
+// elts are the expressions evaluated by the body, to find the loop targets that are not used.
+func (s *Scope) goComprehension(generators []ast.Comprehension, elts []ast.Expr, body func(cs *Scope) jen.Code) *jen.Statement {
+	used := map[string]bool{}
+	for _, g := range generators {
+		usedNames(used, g.Iter)
+		for _, cond := range g.Ifs {
+			usedNames(used, cond)
+		}
+	}
+	for _, elt := range elts {
+		usedNames(used, elt)
+	}
 
-			if len(c.Args) == 1 {
-				stop = s.goExpr(c.Args[0])
-			} else {
-				start = s.goExpr(c.Args[0])
-				stop = s.goExpr(c.Args[1])
+	cs := s.comprehensionScope()
+	var outer, inner *jen.Statement
 
-				if len(c.Args) > 2 {
-					step = s.goExpr(c.Args[2])
-				}
-			}
+	for _, g := range generators {
+		g.Target = unusedTargets(g.Target, used)
 
-			t := s.goExpr(target)
+		outer1, inner1 := cs.gomprehension(g)
+		if outer == nil {
+			outer = outer1
+		} else {
+			inner.Add(jen.Block(outer1))
+		}
+		inner = inner1
+	}
+
+	inner.Add(jen.Block(body(cs)))
+	return outer
+}
 
-			return jen.For(t.Clone().Op(":=").Add(start),
-				t.Clone().Op("<").Add(stop),
-				t.Clone().Op("+=").Add(step)), nil
+// add to used the names in expr
+func usedNames(used map[string]bool, expr ast.Expr) {
+	ast.Walk(expr, func(node ast.Ast) bool {
+		if name, ok := node.(*ast.Name); ok {
+			used[string(name.Id)] = true
 		}
+		return true
+	})
+}
 
-		//
-		// for i, v in enumerate(l)
-		//
-		if n, ok := c.Func.(*ast.Name); ok && string(n.Id) == "enumerate" && len(c.Args) == 1 {
-			return jen.For(s.goExprOrList(target).Op(":=").Range().Add(s.goExpr(c.Args[0]))), nil
+// the target of a comprehension, with the names that are not used replaced by _
+// (Go doesn't allow unused variables), unless none of them is used
+func unusedTargets(target ast.Expr, used map[string]bool) ast.Expr {
+	tuple, ok := target.(*ast.Tuple)
+	if !ok || starredIndex(tuple.Elts) >= 0 {
+		return target
+	}
+
+	elts := make([]ast.Expr, len(tuple.Elts))
+	replaced := 0
+
+	for i, x := range tuple.Elts {
+		elts[i] = x
+		if name, ok := x.(*ast.Name); ok && !used[string(name.Id)] {
+			elts[i] = &ast.Name{Id: "_"}
+			replaced++
 		}
+	}
 
-		//
-		// for v in iterator
-		//
-		//if lenExpr(target) <= 1 {
-		//    return jen.For(jen.List(jen.Op("_"), s.goExpr(target)).Op(":=").Range().Add(s.goExpr(iter))), nil
-		//}
+	if replaced == 0 || replaced == len(elts) {
+		return target
 	}
 
-	// for x in iterable
-	// for k, v in dict
-	// for a,b,c in tuple iterable
+	return &ast.Tuple{Elts: elts}
+}
 
-	switch lenExpr(target) {
-	case 0:
-		log.Fatalf("for without target: %#v", target)
+// the capacity of the result of a comprehension, if it is known before the loop: the length of
+// the iterable (a name) for a single generator without conditions (nil if not known)
+func (s *Scope) goComprehensionSize(generators []ast.Comprehension) *jen.Statement {
+	if len(generators) != 1 || len(generators[0].Ifs) > 0 {
+		return nil
+	}
+
+	iter := generators[0].Iter
+	if c, ok := iter.(*ast.Call); ok {
+		n, ok := c.Func.(*ast.Name)
+		if !ok || string(n.Id) != "range" || len(c.Args) != 1 || bigInts || s.exprType(c.Args[0]).Kind != KindInt || !isSimple(c.Args[0]) {
+			return nil
+		}
+
+		if n, ok := intConst(c.Args[0]); ok && n >= 0 { // range(n)
+			return jen.Lit(n)
+		}
+		return jen.Id("max").Call(s.goExpr(c.Args[0]), jen.Lit(0))
+	}
 
-	case 1:
-		return jen.For(jen.List(jen.Op("_"), s.goExpr(target)).Op(":=").Range().Add(s.goExpr(iter))), nil
+	if _, ok := iter.(*ast.Name); !ok {
+		return nil
+	}
 
-	case 2:
-		return jen.For(s.goExprOrList(target).Op(":=").Range().Add(s.goExpr(iter))), nil
+	switch t := s.exprType(iter); {
+	case t.ordered():
+		return s.goExpr(iter).Dot("Len").Call()
 
-	default:
-		t := target.(*ast.Tuple)
-		return jen.For(jen.Id("_t").Commentf("/* %s */", s.strExprList(t.Elts)).Op(":=").Range().Add(s.goExpr(iter))), t.Elts
+	case t.Kind == KindStr, t.Kind == KindList, t.Kind == KindTuple, t.Kind == KindDict, t.Kind == KindSet:
+		return jen.Len(s.goExpr(iter))
 	}
 
-	return nil, nil // shouldn't get here
+	return nil
 }
 
-func (s *Scope) goAssign(assign *ast.Assign) (*jen.Statement, *jen.Statement, *jen.Statement) {
-	goType := goAny.Clone()
+// assign the conditional expression v to target with an if/else statement
+// (a chain of them, for conditional expressions in the else branch).
+// The values can be lowered to statements in their branch.
+func (s *Scope) goIfExpAssign(v *ast.IfExp, target jen.Code) *jen.Statement {
+	assign := func(value ast.Expr) *jen.Statement {
+		return s.withHoisting(func() *jen.Statement {
+			return jen.Add(target).Op("=").Add(s.goExpr(value))
+		}, value)
+	}
 
-	switch t := assign.Value.(type) {
-	case *ast.Tuple:
-		goType = goTuple.Clone()
+	stmt := jen.If(s.goTest(v.Test)).Block(assign(v.Body)).Else()
+	if orelse, ok := v.Orelse.(*ast.IfExp); ok && len(hoistable(orelse.Test)) == 0 {
+		return stmt.Add(s.goIfExpAssign(orelse, target))
+	}
 
-	case *ast.List:
-		goType = goList.Clone()
+	return stmt.Block(assign(v.Orelse))
+}
 
-	case *ast.Dict:
-		goType = goDict.Clone()
+// a conditional expression that can't be hoisted before its statement: runtime.IfElse if both values
+// can be evaluated before the condition (names and constants), a function literal otherwise
+func (s *Scope) goIfElse(v *ast.IfExp) *jen.Statement {
+	t := s.exprType(v)
 
-	case *ast.Str:
-		goType = jen.String()
+	if isSimple(v.Body) && isSimple(v.Orelse) {
+		ifElse := jen.Qual(goRuntime, "IfElse")
+		if !t.Known() { // the values have different types
+			ifElse.Index(goAny)
+		}
+		return ifElse.Call(s.goTest(v.Test), s.goExpr(v.Body), s.goExpr(v.Orelse))
+	}
 
-	case *ast.Num:
-		switch t.N.(type) {
-		case py.Int:
-			goType = jen.Int()
+	return jen.Func().Params().Add(t.Go()).Block(
+		jen.If(s.goTest(v.Test)).Block(jen.Return(s.goExpr(v.Body))),
+		jen.Return(s.goExpr(v.Orelse))).Call()
+}
 
-		case py.Float:
-			goType = jen.Float64()
+// translate `return value`. A conditional expression returns from each branch.
+func (s *Scope) goReturn(value ast.Expr) *jen.Statement {
+	if v, ok := value.(*ast.IfExp); ok && !s.Top() {
+		return s.withHoisting(func() *jen.Statement {
+			return jen.If(s.goTest(v.Test)).Block(s.goReturn(v.Body))
+		}, v.Test).Line().Add(s.goReturn(v.Orelse))
+	}
 
-		case py.Complex:
-			goType = jen.Complex128()
+	return s.withHoisting(func() *jen.Statement {
+		switch fn := s.function(); {
+		case fn != nil && fn.sig != nil && len(fn.sig.results) > 1: // return a, b
+			return s.goReturnResults(fn.sig, value)
+
+		case fn != nil && fn.sig != nil:
+			return jen.Return(s.goExprAs(value, fn.sig.returns))
 		}
+
+		return jen.Return(s.goExpr(value))
+	}, value)
+}
+
+// translate target op= value
+func (s *Scope) goAugAssign(v *ast.AugAssign) *jen.Statement {
+	if item, key := s.dictItem(v.Target); item != nil { // d[k] op= v
+		return s.goExpr(item).Dot("Set").Call(s.goExpr(key), s.goBinOp(v.Target, v.Op, v.Value))
+	} else if v.Op == ast.BitOr && s.exprType(v.Target).ordered() { // d |= other
+		return s.goExpr(v.Target).Dot("Update").Call(s.goExpr(v.Value))
+	} else if v.Op == ast.Add && s.exprType(v.Target).Kind == KindList { // l += iterable
+		return s.goExtend(v.Target, v.Value)
+	} else if v.Op == ast.Mult && s.exprType(v.Target).Kind == KindList {
+		return s.goExpr(v.Target).Op("=").Add(s.goBinOp(v.Target, v.Op, v.Value))
+	} else if _, ok := setOps[v.Op]; ok && s.exprType(v.Target).Kind == KindSet {
+		return s.goExpr(v.Target).Op("=").Add(s.goBinOp(v.Target, v.Op, v.Value))
+	} else if bigInts && s.exprType(v.Target).Kind == KindInt {
+		return s.goExpr(v.Target).Op("=").Add(s.goBinOp(v.Target, v.Op, v.Value))
+	} else if _, ok := runtimeOps[v.Op]; ok && (v.Op != ast.Add && v.Op != ast.Sub && v.Op != ast.Mult || s.dynamic(v.Target, v.Value)) {
+		return s.goExpr(v.Target).Op("=").Add(s.goBinOp(v.Target, v.Op, v.Value))
 	}
 
-	if len(assign.Targets) == 1 && (isTuple(assign.Targets[0]) || isList(assign.Targets[0])) {
-		return s.goExprOrList(assign.Targets[0]), s.goExprOrList(assign.Value), goType
+	return s.goExpr(v.Target).Add(s.goOpExt(v.Op, "=")).Add(s.goExpr(v.Value))
+}
+
+// name is the target of one of the loops of the comprehension expr
+func comprehensionTarget(expr ast.Expr, name string) bool {
+	var generators []ast.Comprehension
+	switch v := expr.(type) {
+	case *ast.ListComp:
+		generators = v.Generators
+	case *ast.SetComp:
+		generators = v.Generators
+	case *ast.DictComp:
+		generators = v.Generators
 	}
 
-	return s.goExpr(assign.Targets), s.goExpr(assign.Value), goType
+	for _, g := range generators {
+		if slices.Contains(targetNames(g.Target), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// translate target1 = target2 = ... = value, where each target can be a (nested or starred) pattern.
+// With more than one target the value is evaluated once, in a temporary variable (unless it is a name or a constant).
+func (s *Scope) goAssignStmt(targets []ast.Expr, value ast.Expr) *jen.Statement {
+	if name, ok := targets[0].(*ast.Name); ok && len(targets) == 1 && s.hoistScope(value) != nil {
+		// lowered directly into a new variable
+		lowerInto := func() *jen.Statement {
+			t := s.exprType(value)
+			stmt := s.goLowering(value, rename(string(name.Id)))
+			s.vars[string(name.Id)] = t
+			return stmt
+		}
+
+		switch v := value.(type) {
+		case *ast.IfExp: // x = a if c else b
+			if s.isDefined(string(name.Id)) {
+				return s.goIfExpAssign(v, s.goExpr(name))
+			}
+			return lowerInto()
+
+		case *ast.ListComp, *ast.SetComp, *ast.DictComp:
+			if s.isDefined(string(name.Id)) {
+				break
+			}
+
+			if !comprehensionTarget(value, string(name.Id)) {
+				return lowerInto()
+			}
+
+			// w = [w for w in words]: the loop variable would shadow the result
+			tmp := s.generatedName("_v")
+			stmt := s.goLowering(value, tmp).Line().Add(s.goDefine([]jen.Code{jen.Id(rename(string(name.Id)))}, jen.Id(tmp)))
+			s.vars[string(name.Id)] = s.exprType(value)
+			return stmt
+		}
+	}
+
+	stmt := jen.Null()
+
+	if len(targets) > 1 && !isSimple(value) {
+		tmp := s.generatedName("_v")
+		stmt.Add(s.goDefine([]jen.Code{jen.Id(tmp)}, s.goExpr(value))).Line()
+		s.addName(ast.Identifier(tmp), s.exprType(value))
+		value = &ast.Name{Id: ast.Identifier(tmp)}
+	}
+
+	for i, target := range targets {
+		if i > 0 {
+			stmt.Line()
+		}
+
+		var u unpacking
+		if elts, ok := patternElts(target); ok && len(s.callResults(value)) == len(elts) && isFlat(elts) { // a, b = f()
+			u.targets = elts
+			u.types = s.callResults(value)
+			u.values = []jen.Code{s.goCall(value.(*ast.Call))}
+		} else {
+			s.unpackTarget(&u, target, value, nil, nil)
+		}
+		stmt.Add(s.goUnpacked(&u))
+	}
+
+	return stmt
 }
 
 // parse a block/list of statements anre returns
@@ -1239,26 +6615,69 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 			var receiver jen.Code
 			var returns jen.Code
 
+			fname := string(v.Name)
+			if classname != "" {
+				fname = classname + "." + fname
+			}
+
+			sig, ok := s.funcs[fname]
+			if !ok { // nested function
+				sig = newSignature(fname, v, classname != "")
+				s.funcs[fname] = sig
+			}
+
+			if len(sig.optional) > 0 {
+				s.Add(s.goDefaults(sig, s.level < 1 || classname != ""))
+			}
+
 			for _, d := range v.DecoratorList {
 				s.Add(jen.Commentf("// @%v\n", s.goExpr(d).GoString()))
 			}
 
 			ss := s.Push()
+			if classname != "" {
+				ss.class = classname
+			}
+
+			ss.names = newFuncNames(v.Body)
+			ss.sig = sig
+			ss.funcs = map[string]*Signature{} // the nested functions
+			s.checkNonlocals(v, ss.names)
 
-			arguments, recv := ss.goFunctionArguments(v.Args, classname != "")
+			arguments, recv := ss.goFunctionArguments(v.Args, classname != "", sig)
 			if recv != nil {
 				receiver = jen.Params(goId(recv.Arg).Op("*").Id(classname))
+				ss.addName(recv.Arg, objectOf(classname))
 			}
-			if v.Returns != nil && !isNone(v.Returns) {
-				returns = jen.Params(ss.goExprOrList(v.Returns))
+			if v.Returns != nil && !isNone(v.Returns) && len(sig.results) == 0 {
+				if t := annotationType(v.Returns); t.Known() {
+					returns = jen.Params(t.Go())
+				} else {
+					returns = jen.Params(ss.goExprOrList(v.Returns))
+				}
 			}
 
 			stmt := jen.Func()
 			if receiver != nil {
-				if string(v.Name) == "__str__" {
+				// special methods that have a Go equivalent
+				switch string(v.Name) {
+				case "__str__":
 					stmt.Add(receiver).Id("String")
 					returns = jen.Params(jen.Id("string"))
-				} else {
+
+				case "__bool__":
+					stmt.Add(receiver).Id("Bool")
+					returns = jen.Params(jen.Bool())
+
+				case "__len__":
+					stmt.Add(receiver).Id("Len")
+					returns = jen.Params(jen.Int())
+
+				case "__iter__": // used by runtime.Iterate
+					stmt.Add(receiver).Id("Iter")
+					returns = jen.Params(goAny)
+
+				default:
 					stmt.Add(receiver).Add(goId(v.Name))
 				}
 			} else if s.level < 1 {
@@ -1268,7 +6687,12 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 			}
 
 			ss.returnType = ReturnNone
+			ss.Add(ss.goOptionalArguments(sig, v.Body))
+			ss.Add(ss.goHoisted(v.Body))
 			parsed := ss.parseBody("", v.Body)
+			if returns == nil && len(sig.results) > 1 {
+				returns = sig.goResults()
+			}
 			if returns == nil && ss.returnType != ReturnNone {
 				returns = goAny
 			}
@@ -1353,15 +6777,10 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 			ss.Pop(true) // after s.Add(classdef), to add the methods after the type definition
 
 		case *ast.Assign:
-			target, value, _ := s.goAssign(v)
-			stmt := target.Op("=").Add(value)
-			if s.newNames(v.Targets) {
-				stmt = jen.Var().Add(stmt)
-			}
-			s.Add(stmt)
+			s.Add(s.withHoisting(func() *jen.Statement { return s.goAssignStmt(v.Targets, v.Value) }, v.Value))
 
 		case *ast.AugAssign:
-			s.Add(s.goExpr(v.Target).Add(s.goOpExt(v.Op, "=")).Add(s.goExpr(v.Value)))
+			s.Add(s.withHoisting(func() *jen.Statement { return s.goAugAssign(v) }, v.Target, v.Value))
 
 		case *ast.ExprStmt:
 			switch xStmt := v.Value.(type) {
@@ -1383,6 +6802,9 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 				s.Add(jen.Return(ret).Comment("yield from"))
 				s.returnType = ReturnYield
 
+			case *ast.Call:
+				s.Add(s.withHoisting(func() *jen.Statement { return s.goExpr(xStmt) }, xStmt))
+
 			default:
 				s.Add(s.goExpr(v.Value)) //.Line()
 			}
@@ -1391,29 +6813,35 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 			s.Add(jen.Comment("pass"))
 
 		case *ast.Break:
-			s.Add(jen.Break())
+			s.Add(s.goLoopExit(true))
 
 		case *ast.Continue:
-			s.Add(jen.Continue())
+			s.Add(s.goLoopExit(false))
 
 		case *ast.Return:
 			if v.Value == nil {
 				s.Add(jen.Return())
 			} else {
-				s.Add(jen.Return(s.goExprOrList(v.Value)))
+				s.Add(s.goReturn(v.Value))
 			}
 			s.returnType = ReturnReturn
 
 		case *ast.If:
+			pre, test := s.hoisting(func() *jen.Statement { return s.goTest(v.Test) }, v.Test)
+			if pre != nil {
+				s.Add(pre)
+			}
+
 			ss := s.Push()
-			stmt := jen.If(s.goExpr(v.Test))
+			stmt := jen.If(test)
 			if s.Top() && isNameMain(v.Test) && len(v.Orelse) == 0 {
 				stmt = jen.Func().Id("main").Params()
 				s.main = true
 			}
 			stmt.Block(ss.parseBody("", v.Body))
 			if len(v.Orelse) > 0 {
-				if _, ok := v.Orelse[0].(*ast.If); ok && len(v.Orelse) == 1 {
+				// elif, unless the condition needs statements before it
+				if elif, ok := v.Orelse[0].(*ast.If); ok && len(v.Orelse) == 1 && len(hoistable(elif.Test)) == 0 {
 					stmt.Else().Add(ss.parseBody("", v.Orelse))
 				} else {
 					stmt.Else().Block(ss.parseBody("", v.Orelse))
@@ -1424,41 +6852,49 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 
 		case *ast.For:
 			ss := s.Push()
-			stmt, targets := ss.goFor(v.Target, v.Iter)
-			assgn := jen.Null()
-			if targets != nil {
-				assgn = ss.goExprList(targets).Op(":=").ListFunc(func(g *jen.Group) {
-					for i := range targets {
-						g.Add(jen.Id("_t").Index(jen.Lit(i)))
-					}
-				})
+			reuse := ss.allDefined(v.Target)
+
+			var targets ast.Expr
+			pre, stmt := s.hoisting(func() (stmt *jen.Statement) {
+				stmt, targets = ss.goFor(v.Target, v.Iter, true)
+				return
+			}, v.Iter)
+			if pre != nil {
+				s.Add(pre)
 			}
-			stmt.Block(assgn, ss.parseBody("", v.Body))
-			if len(v.Orelse) > 0 {
-				stmt.Else().Block(ss.parseBody("", v.Orelse))
+			define := ":="
+			if reuse {
+				define = "="
 			}
+			ss.control = s.newLoopControl(v.Body, v.Orelse)
+			stmt.Block(ss.goLoopTargets(targets, define), ss.parseBody("", v.Body))
 			ss.Pop(false)
-			s.Add(stmt)
+			s.addLoop(stmt, ss.control, v.Orelse)
 
 		case *ast.While:
 			ss := s.Push()
-			stmt := jen.For(ss.goExpr(v.Test))
+			stmt := jen.For(ss.goTest(v.Test))
 			if k, ok := v.Test.(*ast.NameConstant); ok && k.Value == py.True {
 				stmt = jen.For()
 			}
+			ss.control = s.newLoopControl(v.Body, v.Orelse)
 			stmt = stmt.Block(ss.parseBody("", v.Body))
-			if len(v.Orelse) > 0 {
-				stmt.Else().Block(ss.parseBody("", v.Orelse))
-			}
 			ss.Pop(false)
-			s.Add(stmt)
+			s.addLoop(stmt, ss.control, v.Orelse)
 
 		case *ast.Try:
 			ss := s.Push()
+
+			// the body is a closure: break and continue set a flag, checked after the try statement
+			control := &loopControl{kind: controlTry}
+			ss.control = control
+			tryBody := ss.parseBody("", v.Body)
+			ss.control = &loopControl{kind: controlExcept}
+
 			stmt := jen.If(
 				jen.Err().Op(":=").Func().Params().Params(goException).Block(
 					jen.Comment("try"),
-					ss.parseBody("", v.Body),
+					tryBody,
 				).Call(),
 				jen.Err().Op("!=").Nil())
 
@@ -1471,7 +6907,21 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 					for _, h := range v.Handlers {
 						ch := jen.Case(ss.goExpr(h.ExprType))
 						if h.Name != "" {
-							ch.Block(jen.Commentf("as %v", h.Name), ss.parseBody("", h.Body))
+							// the exception is bound to the name only if it's used (Go doesn't allow unused variables)
+							name := string(h.Name)
+							switch {
+							case ss.isDefined(name): // declared at the top of the function
+								ch.Block(goId(h.Name).Op("=").Err(), ss.parseBody("", h.Body))
+
+							case usedOutside(h.Body, nil, name):
+								hs := ss.Push()
+								hs.addName(h.Name, typeAny)
+								ch.Block(goId(h.Name).Op(":=").Err(), hs.parseBody("", h.Body))
+								hs.Pop(false)
+
+							default:
+								ch.Block(jen.Commentf("as %v", h.Name), ss.parseBody("", h.Body))
+							}
 						} else {
 							ch.Block(ss.parseBody("", h.Body))
 						}
@@ -1482,15 +6932,51 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 			}
 
 			stmt.Block(body)
+			ss.control = nil
 
 			if len(v.Orelse) > 0 {
-				stmt.Else().Block(ss.parseBody("", v.Orelse))
+				orelse := ss.parseBody("", v.Orelse)
+
+				// the body completes normally also after break or continue, that skip the else clause
+				var completed *jen.Statement
+				for _, flag := range []string{control.breakFlag, control.continueFlag} {
+					switch {
+					case flag == "":
+					case completed == nil:
+						completed = jen.Op("!").Id(flag)
+					default:
+						completed.Op("&&").Op("!").Id(flag)
+					}
+				}
+				if completed != nil {
+					orelse = jen.If(completed).Block(orelse)
+				}
+
+				stmt.Else().Block(orelse)
 			}
 
 			if len(v.Finalbody) > 0 {
 				stmt.Line().Block(jen.Comment("finally"), ss.parseBody("", v.Finalbody))
 			}
 			ss.Pop(false)
+
+			if c := control; c.breakFlag != "" || c.continueFlag != "" {
+				block := []jen.Code{}
+				for _, flag := range []string{c.breakFlag, c.continueFlag} {
+					if flag != "" {
+						block = append(block, jen.Var().Id(flag).Bool())
+					}
+				}
+
+				block = append(block, stmt)
+				if c.breakFlag != "" {
+					block = append(block, jen.If(jen.Id(c.breakFlag)).Block(s.goLoopExit(true)))
+				}
+				if c.continueFlag != "" {
+					block = append(block, jen.If(jen.Id(c.continueFlag)).Block(s.goLoopExit(false)))
+				}
+				stmt = jen.Block(block...)
+			}
 			s.Add(stmt)
 
 		case *ast.Raise:
@@ -1501,11 +6987,12 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 			s.Add(stmt)
 
 		case *ast.Assert:
-			if v.Msg != nil {
-				s.Add(goAssert.Call(s.goExpr(v.Test), s.goExpr(v.Msg)))
-			} else {
-				s.Add(goAssert.Call(s.goExpr(v.Test), jen.Lit("")))
-			}
+			s.Add(s.withHoisting(func() *jen.Statement {
+				if v.Msg != nil {
+					return goAssert.Call(s.goTest(v.Test), s.goExpr(v.Msg))
+				}
+				return goAssert.Call(s.goTest(v.Test), jen.Lit(""))
+			}, v.Test))
 
 		case *ast.Global:
 			s.Add(jen.Commentf("global %v", s.strIdentifiers(v.Names)))
@@ -1515,7 +7002,11 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 
 		case *ast.Delete:
 			for _, t := range v.Targets {
-				if st, ok := t.(*ast.Subscript); ok {
+				if item, key := s.dictItem(t); item != nil {
+					s.Add(s.goExpr(item).Dot("Delete").Call(s.goExpr(key)))
+				} else if st, ok := t.(*ast.Subscript); ok && s.exprType(st.Value).Kind == KindList {
+					s.Add(s.goDeleteItems(st.Value, st.Slice))
+				} else if st, ok := t.(*ast.Subscript); ok {
 					if i, ok := st.Slice.(*ast.Index); ok {
 						s.Add(jen.Delete(s.goExpr(st.Value), s.goExpr(i.Value)))
 					} else {
@@ -1535,7 +7026,11 @@ func (s *Scope) parseBody(classname string, body []ast.Stmt) *jen.Statement {
 
 				for _, item := range v.Items {
 					if item.OptionalVars != nil {
-						g.Add(ss.goExpr(item.OptionalVars).Op(":=").Add(ss.goExpr(item.ContextExpr)))
+						define := ":="
+						if ss.allDefined(item.OptionalVars) {
+							define = "="
+						}
+						g.Add(ss.goExpr(item.OptionalVars).Op(define).Add(ss.goExpr(item.ContextExpr)))
 					} else {
 						g.Add(ss.goExpr(item.ContextExpr))
 					}
@@ -1563,7 +7058,8 @@ func main() {
 	flag.BoolVar(&verbose, "verbose", verbose, "print statement and expressions")
 	flag.BoolVar(&lineno, "lines", lineno, "add source line numbers")
 
-	ignore := flag.Bool("ignore", false, "ignore errors")
+	flag.BoolVar(&ignoreErrors, "ignore", ignoreErrors, "ignore errors")
+	flag.BoolVar(&bigInts, "bigint", bigInts, "translate python ints to arbitrary precision runtime.Int")
 	flag.Parse()
 
 	parser.SetDebug(debugLevel)
@@ -1604,8 +7100,16 @@ func main() {
 
 		scope := NewScope(f)
 		//scope.file.ImportAlias(goRuntime, ".")
+		scope.collectSignatures("", m.Body)
+		scope.collectGlobals(m.Body)
 		scope.parseBody("", m.Body)
 
+		if globals := scope.goGlobals(); globals != nil {
+			// package level variables can be declared anywhere, so they go at the end
+			scope.Add(globals)
+			scope.Render()
+		}
+
 		if scope.main {
 			pname = "main"
 		}
@@ -1620,7 +7124,7 @@ func main() {
 
 		for _, s := range stmts {
 			if err := s.Render(os.Stdout); err != nil {
-				if *ignore {
+				if ignoreErrors {
 					fmt.Println("ERROR:", err)
 				} else {
 					log.Fatal(err)
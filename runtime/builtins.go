@@ -0,0 +1,362 @@
+package runtime
+
+import "fmt"
+import "hash/maphash"
+import "iter"
+import "math"
+import "math/cmplx"
+import "reflect"
+
+//
+// len(v)
+//
+func Len(v Any) int {
+	switch c := v.(type) {
+	case string:
+		return len(c)
+
+	case List: // or Tuple
+		return len(c)
+
+	case Dict:
+		return len(c)
+
+	case interface{ Len() int }: // Set, *OrderedDict and objects implementing __len__
+		return c.Len()
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		return rv.Len()
+	}
+
+	panic(fmt.Sprintf("TypeError: object of type '%v' has no len()", typeName(v)))
+}
+
+//
+// sorted(iterable[, reverse=True]): a new sorted list with the elements of iterable
+//
+func Sorted(iterable Any, reverse bool) List {
+	l := ListFrom(iterable)
+	ListSort(l, reverse)
+	return l
+}
+
+//
+// min(iterable[, default]): the smallest element of iterable
+// (panics with ValueError if iterable is empty and there is no default)
+//
+func Min(iterable Any, def ...Any) Any {
+	return minmax("min", iterable, def, -1)
+}
+
+//
+// max(iterable[, default]): the largest element of iterable
+// (panics with ValueError if iterable is empty and there is no default)
+//
+func Max(iterable Any, def ...Any) Any {
+	return minmax("max", iterable, def, 1)
+}
+
+func minmax(name string, iterable Any, def []Any, sign int) Any {
+	var m Any
+	empty := true
+
+	for v := range Iterate(iterable) {
+		if empty || Compare(v, m)*sign > 0 {
+			m = v
+		}
+		empty = false
+	}
+
+	if empty {
+		if len(def) > 0 {
+			return def[0]
+		}
+
+		panic(fmt.Sprintf("ValueError: %v() arg is an empty sequence", name))
+	}
+
+	return m
+}
+
+//
+// sum(iterable[, start]): start (0 by default) plus the elements of iterable
+//
+func Sum(iterable Any, start ...Any) Any {
+	var s Any = 0
+	if len(start) > 0 {
+		if _, ok := start[0].(string); ok {
+			panic("TypeError: sum() can't sum strings [use ''.join(seq) instead]")
+		}
+
+		s = start[0]
+	}
+
+	for v := range Iterate(iterable) {
+		s = Add(s, v)
+	}
+
+	return s
+}
+
+//
+// abs(v)
+//
+func Abs(v Any) Any {
+	switch n := v.(type) {
+	case bool:
+		return AbsInt(toInt(n))
+	case int:
+		return AbsInt(n)
+	case Int:
+		return n.Abs()
+	}
+
+	switch numberLevel(v) {
+	case intNumber:
+		return AbsInt(toInt(v))
+	case floatNumber:
+		return math.Abs(toFloat(v))
+	case complexNumber:
+		return cmplx.Abs(toComplex(v))
+	}
+
+	panic(fmt.Sprintf("TypeError: bad operand type for abs(): '%v'", typeName(v)))
+}
+
+//
+// abs(n) for Go ints
+//
+func AbsInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+//
+// round(x[, ndigits]): round to the nearest integer (or to ndigits decimal digits),
+// rounding halves to even as python does
+//
+func Round(x Any, ndigits ...int) Any {
+	switch numberLevel(x) {
+	case intNumber:
+		if len(ndigits) > 0 && ndigits[0] < 0 {
+			p := Pow(10, -ndigits[0])
+			return Sub(x, Mod(x, p))
+		}
+		return x
+
+	case floatNumber:
+		if len(ndigits) > 0 {
+			return RoundFloat(toFloat(x), ndigits[0])
+		}
+		return int(math.RoundToEven(toFloat(x)))
+	}
+
+	panic(fmt.Sprintf("TypeError: type %v doesn't define __round__ method", typeName(x)))
+}
+
+//
+// round(x, ndigits) for floats
+//
+func RoundFloat(x float64, ndigits int) float64 {
+	p := math.Pow(10, float64(ndigits))
+	return math.RoundToEven(x*p) / p
+}
+
+//
+// divmod(a, b): the tuple (a // b, a % b)
+//
+func DivMod(a, b Any) Tuple {
+	return Tuple{FloorDiv(a, b), Mod(a, b)}
+}
+
+//
+// any(iterable): true if any element of iterable is true
+//
+func AnyOf(iterable Any) bool {
+	for v := range Iterate(iterable) {
+		if Truthy(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//
+// all(iterable): true if all the elements of iterable are true
+//
+func AllOf(iterable Any) bool {
+	for v := range Iterate(iterable) {
+		if !Truthy(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+//
+// reversed(seq): a list with the elements of seq in reverse order
+//
+func Reversed(seq Any) List {
+	l := ListFrom(seq)
+	Reverse(l)
+	return l
+}
+
+//
+// zip(iterables...): a list of tuples, where the i-th tuple contains the i-th element
+// of each iterable. The list is as long as the shortest iterable
+//
+func Zip(iterables ...Any) List {
+	if len(iterables) == 0 {
+		return List{}
+	}
+
+	// the elements are pulled one at a time, so that the iterables
+	// are not consumed past the end of the shortest
+	nexts := make([]func() (Any, bool), len(iterables))
+	for i, it := range iterables {
+		next, stop := iter.Pull(Iterate(it))
+		defer stop()
+		nexts[i] = next
+	}
+
+	z := List{}
+	for {
+		t := make(Tuple, len(nexts))
+		for i, next := range nexts {
+			v, ok := next()
+			if !ok {
+				return z
+			}
+			t[i] = v
+		}
+		z = append(z, t)
+	}
+}
+
+//
+// enumerate(iterable, start): a list of (index, element) tuples
+//
+func Enumerate(iterable Any, start int) List {
+	e := List{}
+	for v := range Iterate(iterable) {
+		e = append(e, Tuple{start + len(e), v})
+	}
+
+	return e
+}
+
+//
+// Iterate over the (index, element) pairs of l, with the indexes counting from start
+// (as in `for i, v in enumerate(l, start)`)
+//
+func Enumerated[T any](l []T, start int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, v := range l {
+			if !yield(start+i, v) {
+				return
+			}
+		}
+	}
+}
+
+//
+// Iterate over the pairs of elements of a and b, stopping at the end of the shortest
+// (as in `for x, y in zip(a, b)`)
+//
+func Zip2[A, B any](a []A, b []B) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		for i := range min(len(a), len(b)) {
+			if !yield(a[i], b[i]) {
+				return
+			}
+		}
+	}
+}
+
+//
+// chr(i): the string with the character with code point i
+//
+func Chr(i Any) string {
+	n := toBigInt(i)
+	if n.Sign() < 0 || n.Cmp(NewInt(0x10ffff)) > 0 {
+		panic("ValueError: chr() arg not in range(0x110000)")
+	}
+
+	return string(rune(n.Int()))
+}
+
+//
+// ord(c): the code point of the (one character) string c
+//
+func Ord(c Any) int {
+	s, ok := c.(string)
+	if !ok {
+		panic(fmt.Sprintf("TypeError: ord() expected string of length 1, but %v found", typeName(c)))
+	}
+
+	r := []rune(s)
+	if len(r) != 1 {
+		panic(fmt.Sprintf("TypeError: ord() expected a character, but string of length %v found", len(r)))
+	}
+
+	return int(r[0])
+}
+
+//
+// hex(i), as 0x1f or -0x1f
+//
+func Hex(i Any) string {
+	return formatInt(i, "%#x")
+}
+
+//
+// oct(i), as 0o17 or -0o17
+//
+func Oct(i Any) string {
+	return formatInt(i, "%O")
+}
+
+//
+// bin(i), as 0b101 or -0b101
+//
+func Bin(i Any) string {
+	return formatInt(i, "%#b")
+}
+
+func formatInt(i Any, format string) string {
+	if numberLevel(i) != intNumber {
+		panic(fmt.Sprintf("TypeError: '%v' object cannot be interpreted as an integer", typeName(i)))
+	}
+
+	return fmt.Sprintf(format, toBigInt(i).Big())
+}
+
+var hashSeed = maphash.MakeSeed()
+
+//
+// hash(v): equal values (as 1, 1.0 and True) have the same hash.
+// As in python, the hash of strings changes between runs
+//
+func Hash(v Any) int {
+	switch k := hashKey(v).(type) {
+	case int:
+		return k
+
+	case string:
+		return int(maphash.String(hashSeed, k))
+
+	case tupleKey:
+		return int(maphash.String(hashSeed, string(k)))
+
+	default: // the Go representation of the other comparable values identifies them
+		return int(maphash.String(hashSeed, fmt.Sprintf("%T:%#v", k, k)))
+	}
+}
@@ -0,0 +1,120 @@
+package runtime
+
+import "testing"
+
+func TestMinMaxSum(t *testing.T) {
+	l := List{3, 1.5, 7}
+
+	if v := Min(l); v != 1.5 {
+		t.Error("min should be 1.5, got", v)
+	}
+
+	if v := Max(l); v != 7 {
+		t.Error("max should be 7, got", v)
+	}
+
+	if v := Max(List{}, "none"); v != "none" {
+		t.Error("max of an empty list should be the default, got", v)
+	}
+
+	if v := Sum(l); v != 11.5 {
+		t.Error("sum should be 11.5, got", v)
+	}
+
+	if v := Sum(List{1, 2}, 10); v != 13 {
+		t.Error("sum with start 10 should be 13, got", v)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("min of an empty list should raise ValueError")
+		}
+	}()
+
+	Min(List{})
+}
+
+func TestNumberBuiltins(t *testing.T) {
+	if v := Abs(-3); v != 3 {
+		t.Error("abs(-3) should be 3, got", v)
+	}
+
+	if v := Abs(complex(3, 4)); v != 5.0 {
+		t.Error("abs(3+4j) should be 5.0, got", v)
+	}
+
+	if v := Round(2.5); v != 2 {
+		t.Error("round(2.5) should be 2, got", v)
+	}
+
+	if v := Round(1234, -2); v != 1200 {
+		t.Error("round(1234, -2) should be 1200, got", v)
+	}
+
+	if v := RoundFloat(1.256, 2); v != 1.26 {
+		t.Error("round(1.256, 2) should be 1.26, got", v)
+	}
+
+	if v := DivMod(-7, 2); !Eq(v, Tuple{-4, 1}) {
+		t.Error("divmod(-7, 2) should be (-4, 1), got", v)
+	}
+
+	if v := Hex(-31); v != "-0x1f" {
+		t.Error("hex(-31) should be -0x1f, got", v)
+	}
+
+	if v := Oct(15); v != "0o17" {
+		t.Error("oct(15) should be 0o17, got", v)
+	}
+
+	if v := Bin(MustParseInt("18446744073709551616")); v != "0b1"+"0000000000000000000000000000000000000000000000000000000000000000" {
+		t.Error("bin(2**64) failed, got", v)
+	}
+
+	if Hash(1) != Hash(1.0) || Hash(1) != Hash(true) || Hash("a") != Hash("a") || Hash(Tuple{1, "a"}) != Hash(Tuple{1.0, "a"}) {
+		t.Error("equal values should have the same hash")
+	}
+
+	if Chr(233) != "é" || Ord("é") != 233 {
+		t.Error("chr(233) and ord('é') failed")
+	}
+}
+
+func TestIterables(t *testing.T) {
+	if v := Len(NewSet(1, 2)); v != 2 {
+		t.Error("len of a set of 2 elements should be 2, got", v)
+	}
+
+	if !AnyOf(List{0, "", 1}) || AnyOf(List{}) || AllOf(List{1, 0}) || !AllOf("ab") {
+		t.Error("any or all failed")
+	}
+
+	if v := Zip(List{1, 2, 3}, "ab"); !Eq(v, List{Tuple{1, "a"}, Tuple{2, "b"}}) {
+		t.Error("zip([1, 2, 3], 'ab') failed, got", v)
+	}
+
+	if v := Enumerate(List{"a", "b"}, 1); !Eq(v, List{Tuple{1, "a"}, Tuple{2, "b"}}) {
+		t.Error("enumerate(['a', 'b'], 1) failed, got", v)
+	}
+
+	if v := Sorted(NewSet(3, 1, 2), true); !Eq(v, List{3, 2, 1}) {
+		t.Error("sorted({3, 1, 2}, reverse=True) failed, got", v)
+	}
+
+	if v := Reversed(List{1, 2, 3}); !Eq(v, List{3, 2, 1}) {
+		t.Error("reversed([1, 2, 3]) failed, got", v)
+	}
+
+	n := 0
+	for i, s := range Zip2([]int{1, 2, 3}, []string{"a", "b"}) {
+		n += i + len(s)
+	}
+	if n != 5 {
+		t.Error("Zip2 should stop at the end of the shortest slice")
+	}
+
+	it := Iter(List{1, 2})
+	if Next(it) != 1 || Next(it) != 2 || Next(it, "end") != "end" {
+		t.Error("iter/next failed")
+	}
+}
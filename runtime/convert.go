@@ -0,0 +1,357 @@
+package runtime
+
+import "fmt"
+import "math"
+import "math/big"
+import "reflect"
+import "sort"
+import "strconv"
+import "strings"
+import "unicode"
+
+//
+// int(v[, base]): convert a number (truncating floats toward zero) or a string to an Int.
+// Strings can have leading and trailing spaces, a sign and underscores between digits;
+// base 0 means the base is given by the prefix (0x, 0o, 0b or none for decimal)
+//
+func IntFrom(v Any, base ...int) Int {
+	if s, ok := v.(string); ok {
+		b := 10
+		if len(base) > 0 {
+			b = base[0]
+		}
+
+		return parseInt(s, b)
+	}
+
+	if len(base) > 0 {
+		panic("TypeError: int() can't convert non-string with explicit base")
+	}
+
+	switch numberLevel(v) {
+	case intNumber:
+		return toBigInt(v)
+
+	case floatNumber:
+		f := toFloat(v)
+		switch {
+		case math.IsNaN(f):
+			panic("ValueError: cannot convert float NaN to integer")
+		case math.IsInf(f, 0):
+			panic("OverflowError: cannot convert float infinity to integer")
+		}
+
+		i, _ := big.NewFloat(math.Trunc(f)).Int(nil)
+		return fromBig(i)
+	}
+
+	panic(fmt.Sprintf("TypeError: int() argument must be a string or a real number, not '%v'", typeName(v)))
+}
+
+//
+// The value of the int v as a Go int, as for python indexes, counts and range() arguments
+// (panics with TypeError if v is not an int)
+//
+func Index(v Any) int {
+	if numberLevel(v) != intNumber {
+		panic(fmt.Sprintf("TypeError: '%v' object cannot be interpreted as an integer", typeName(v)))
+	}
+
+	n := toBigInt(v)
+	if !n.IsSmall() {
+		panic("IndexError: cannot fit 'int' into an index-sized integer")
+	}
+
+	return n.Int()
+}
+
+//
+// parse s as a python int literal in base
+//
+func parseInt(s string, base int) Int {
+	invalid := func() Int {
+		panic(fmt.Sprintf("ValueError: invalid literal for int() with base %v: %v", base, Repr(s)))
+	}
+
+	if base != 0 && (base < 2 || base > 36) {
+		panic("ValueError: int() base must be >= 2 and <= 36, or 0")
+	}
+
+	digits := strings.TrimSpace(s)
+
+	neg := false
+	if len(digits) > 0 && (digits[0] == '+' || digits[0] == '-') {
+		neg = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	// the prefix, that is required with base 0 and optional with the matching base
+	prefixed := false
+	if len(digits) > 1 && digits[0] == '0' {
+		switch p := unicode.ToLower(rune(digits[1])); {
+		case p == 'x' && (base == 0 || base == 16):
+			base, prefixed = 16, true
+		case p == 'o' && (base == 0 || base == 8):
+			base, prefixed = 8, true
+		case p == 'b' && (base == 0 || base == 2):
+			base, prefixed = 2, true
+		}
+	}
+
+	if prefixed {
+		digits = strings.TrimPrefix(digits[2:], "_")
+	} else if base == 0 {
+		if strings.TrimLeft(digits, "0_") != "" && digits[0] == '0' { // no leading zeros for decimal numbers
+			return invalid()
+		}
+		base = 10
+	}
+
+	// underscores are only allowed between digits
+	if digits == "" || digits[0] == '_' || digits[len(digits)-1] == '_' || strings.Contains(digits, "__") ||
+		digits[0] == '+' || digits[0] == '-' {
+		return invalid()
+	}
+
+	i, ok := new(big.Int).SetString(strings.ReplaceAll(digits, "_", ""), base)
+	if !ok {
+		return invalid()
+	}
+
+	if neg {
+		i.Neg(i)
+	}
+
+	return fromBig(i)
+}
+
+//
+// float(v): convert a number or a string to a float64.
+// Strings can have leading and trailing spaces, underscores between digits
+// and can be "inf", "infinity" or "nan" (with an optional sign)
+//
+func Float(v Any) float64 {
+	if s, ok := v.(string); ok {
+		digits := strings.TrimSpace(s)
+
+		valid := digits != "" && !strings.ContainsAny(digits, "xXpP") // no hex floats
+		if strings.Contains(digits, "_") {
+			valid = valid && validUnderscores(digits)
+			digits = strings.ReplaceAll(digits, "_", "")
+		}
+
+		f, err := strconv.ParseFloat(digits, 64)
+		if err != nil && err.(*strconv.NumError).Err == strconv.ErrRange {
+			err = nil // python returns inf (or 0) as well
+		}
+
+		if !valid || err != nil {
+			panic(fmt.Sprintf("ValueError: could not convert string to float: %v", Repr(s)))
+		}
+
+		return f
+	}
+
+	switch numberLevel(v) {
+	case intNumber, floatNumber:
+		return toFloat(v)
+	}
+
+	panic(fmt.Sprintf("TypeError: float() argument must be a string or a real number, not '%v'", typeName(v)))
+}
+
+//
+// check that each underscore in s is between two digits
+//
+func validUnderscores(s string) bool {
+	for i, c := range s {
+		if c == '_' && (i == 0 || i == len(s)-1 || !isDigit(s[i-1]) || !isDigit(s[i+1])) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+//
+// bool(v): the truth value of v
+//
+func Bool(v Any) bool {
+	return Truthy(v)
+}
+
+//
+// str(v): convert v to a string, as python does.
+// Objects can implement String() string (__str__)
+//
+func Str(v Any) string {
+	switch x := v.(type) {
+	case nil:
+		return "None"
+
+	case bool:
+		if x {
+			return "True"
+		}
+		return "False"
+
+	case string:
+		return x
+
+	case int:
+		return strconv.Itoa(x)
+
+	case float64:
+		return FormatFloat(x)
+
+	case float32:
+		return FormatFloat(float64(x))
+
+	case complex128:
+		return formatComplex(x)
+
+	case List: // or Tuple
+		items := make([]string, len(x))
+		for i, e := range x {
+			items[i] = Repr(e)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+
+	case Dict:
+		items := make([]string, 0, len(x))
+		for k, e := range x {
+			items = append(items, Repr(k)+": "+Repr(e))
+		}
+		sort.Strings(items) // Go maps are not ordered
+		return "{" + strings.Join(items, ", ") + "}"
+
+	case error:
+		return x.Error()
+
+	case fmt.Stringer: // Int, Set, *OrderedDict and objects implementing __str__
+		return x.String()
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Slice, reflect.Array: // typed lists
+		return Str(ListFrom(v))
+
+	case reflect.Map: // typed dicts
+		items := make([]string, 0, rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			items = append(items, Repr(it.Key().Interface())+": "+Repr(it.Value().Interface()))
+		}
+		sort.Strings(items)
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+
+	return fmt.Sprint(v)
+}
+
+//
+// repr(v): the python representation of v (strings are quoted).
+// Objects can implement Repr() string (__repr__)
+//
+func Repr(v Any) string {
+	switch x := v.(type) {
+	case string:
+		return quote(x)
+
+	case interface{ Repr() string }:
+		return x.Repr()
+	}
+
+	return Str(v)
+}
+
+//
+// quote s as python does: with single quotes, unless s contains single quotes and no double quotes
+//
+func quote(s string) string {
+	q := '\''
+	if strings.ContainsRune(s, '\'') && !strings.ContainsRune(s, '"') {
+		q = '"'
+	}
+
+	var b strings.Builder
+	b.WriteRune(q)
+
+	for _, c := range s {
+		switch {
+		case c == q || c == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case unicode.IsPrint(c):
+			b.WriteRune(c)
+		case c < 0x100:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		case c < 0x10000:
+			fmt.Fprintf(&b, `\u%04x`, c)
+		default:
+			fmt.Fprintf(&b, `\U%08x`, c)
+		}
+	}
+
+	b.WriteRune(q)
+	return b.String()
+}
+
+//
+// Format f as python does: the shortest representation that reads back as f,
+// with a decimal point or an exponent (1.0, 0.1, 1e+16, 1e-05, inf, nan)
+//
+func FormatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	exp, _ := strconv.Atoi(e[strings.IndexByte(e, 'e')+1:])
+	if exp < -4 || exp >= 16 {
+		return e
+	}
+
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsRune(s, '.') {
+		s += ".0"
+	}
+
+	return s
+}
+
+//
+// Format c as python does: (1+2j), 2j, (1-0j)
+//
+func formatComplex(c complex128) string {
+	part := func(f float64) string {
+		return strings.TrimSuffix(FormatFloat(f), ".0")
+	}
+
+	re, im := real(c), imag(c)
+	if re == 0 && !math.Signbit(re) {
+		return part(im) + "j"
+	}
+
+	sign := "+"
+	if math.Signbit(im) {
+		sign = "-"
+		im = -im
+	}
+
+	return "(" + part(re) + sign + part(im) + "j)"
+}
@@ -0,0 +1,116 @@
+package runtime
+
+import "math"
+import "testing"
+
+func TestIntFrom(t *testing.T) {
+	tests := []struct {
+		v    Any
+		base []int
+		want string
+	}{
+		{" -42 ", nil, "-42"},
+		{"1_000", nil, "1000"},
+		{"ff", []int{16}, "255"},
+		{"0x_ff", []int{16}, "255"},
+		{"0b101", []int{0}, "5"},
+		{"0o17", []int{0}, "15"},
+		{"00", []int{0}, "0"},
+		{"123456789012345678901234567890", nil, "123456789012345678901234567890"},
+		{-3.9, nil, "-3"},
+		{true, nil, "1"},
+	}
+
+	for _, test := range tests {
+		if v := IntFrom(test.v, test.base...).String(); v != test.want {
+			t.Errorf("int(%v, %v) should be %v, got %v", Repr(test.v), test.base, test.want, v)
+		}
+	}
+
+	for _, s := range []string{"", "1__0", "_1", "1_", "0x10", "010", "+-1", "1.5"} {
+		base := 10
+		if s == "010" {
+			base = 0
+		}
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("int(%v, %v) should raise ValueError", Repr(s), base)
+				}
+			}()
+
+			IntFrom(s, base)
+		}()
+	}
+}
+
+func TestIndex(t *testing.T) {
+	if Index(NewInt(7)) != 7 || Index(true) != 1 || Index(int64(-2)) != -2 {
+		t.Error("Index of ints failed")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Index(1.0) should raise TypeError")
+		}
+	}()
+
+	Index(1.0)
+}
+
+func TestFloat(t *testing.T) {
+	if v := Float(" 1_000.5 "); v != 1000.5 {
+		t.Error("float(' 1_000.5 ') should be 1000.5, got", v)
+	}
+
+	if v := Float("-Infinity"); !math.IsInf(v, -1) {
+		t.Error("float('-Infinity') should be -inf, got", v)
+	}
+
+	if v := Float(NewInt(3)); v != 3.0 {
+		t.Error("float(3) should be 3.0, got", v)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("float('0x1p3') should raise ValueError")
+		}
+	}()
+
+	Float("0x1p3")
+}
+
+func TestStrRepr(t *testing.T) {
+	tests := []struct {
+		v         Any
+		str, repr string
+	}{
+		{nil, "None", "None"},
+		{true, "True", "True"},
+		{5, "5", "5"},
+		{1.0, "1.0", "1.0"},
+		{0.1, "0.1", "0.1"},
+		{1e16, "1e+16", "1e+16"},
+		{1.5e-5, "1.5e-05", "1.5e-05"},
+		{math.Inf(-1), "-inf", "-inf"},
+		{complex(1, -2), "(1-2j)", "(1-2j)"},
+		{complex(0, 2.5), "2.5j", "2.5j"},
+		{"it's", "it's", `"it's"`},
+		{"a\nb", "a\nb", `'a\nb'`},
+		{List{1, "a", nil}, "[1, 'a', None]", "[1, 'a', None]"},
+		{[]float64{1, 2.5}, "[1.0, 2.5]", "[1.0, 2.5]"},
+		{map[string]int{"b": 2, "a": 1}, "{'a': 1, 'b': 2}", "{'a': 1, 'b': 2}"},
+		{NewOrderedDict("b", 2, "a", 1), "{'b': 2, 'a': 1}", "{'b': 2, 'a': 1}"},
+	}
+
+	for _, test := range tests {
+		if s := Str(test.v); s != test.str {
+			t.Errorf("str(%#v) should be %v, got %v", test.v, test.str, s)
+		}
+
+		if s := Repr(test.v); s != test.repr {
+			t.Errorf("repr(%#v) should be %v, got %v", test.v, test.repr, s)
+		}
+	}
+}
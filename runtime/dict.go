@@ -0,0 +1,519 @@
+package runtime
+
+import "fmt"
+import "iter"
+import "maps"
+import "math"
+import "reflect"
+import "slices"
+import "strings"
+
+//
+// OrderedDict is a python dict: the keys can be any hashable value
+// (numbers, strings, tuples) and iteration follows insertion order.
+//
+type OrderedDict struct {
+	entries []dictEntry // in insertion order, including deleted entries
+	index   map[Any]int // hash key -> position in entries
+	deleted int         // number of deleted entries
+}
+
+type dictEntry struct {
+	key     Any
+	value   Any
+	deleted bool
+}
+
+//
+// A tuple used as a dictionary key (tuples are slices, that are not comparable)
+//
+type tupleKey string
+
+//
+// Return a new OrderedDict. The parameters are key, value pairs
+//
+func NewOrderedDict(kv ...Any) *OrderedDict {
+	if len(kv)%2 != 0 {
+		panic("NewOrderedDict: odd number of parameters")
+	}
+
+	d := &OrderedDict{index: map[Any]int{}}
+	for i := 0; i < len(kv); i += 2 {
+		d.Set(kv[i], kv[i+1])
+	}
+
+	return d
+}
+
+//
+// Return a new OrderedDict from a mapping (OrderedDict or Dict) or an iterable of key, value pairs
+//
+func DictFrom(v Any) *OrderedDict {
+	d := NewOrderedDict()
+	d.Update(v)
+	return d
+}
+
+//
+// Return the value used to hash key: python considers equal (and hashes the same)
+// numbers with the same value, as 1, 1.0 and True
+//
+func hashKey(key Any) Any {
+	switch k := key.(type) {
+	case nil, string, int:
+		return k
+
+	case bool:
+		if k {
+			return 1
+		}
+		return 0
+
+	case float64:
+		if k == math.Trunc(k) && math.Abs(k) < math.MaxInt64 {
+			return int(k)
+		}
+		return k
+
+	case Int:
+		if k.IsSmall() {
+			return k.Int()
+		}
+		return tupleKey("int:" + k.String())
+
+	case List: // or Tuple
+		// each element is prefixed by its length, so that the encoding is unambiguous
+		var b strings.Builder
+		for _, v := range k {
+			h := hashKey(v)
+			part := fmt.Sprintf("%T:%#v", h, h)
+			fmt.Fprintf(&b, "%d:%s", len(part), part)
+		}
+		return tupleKey("(" + b.String() + ")")
+	}
+
+	if t := reflect.TypeOf(key); !t.Comparable() || t.Kind() == reflect.Ptr && t.Elem() == reflect.TypeOf(OrderedDict{}) {
+		panic(fmt.Sprintf("TypeError: unhashable type: '%v'", typeName(key)))
+	}
+
+	if numberLevel(key) == intNumber {
+		return toInt(key)
+	}
+
+	return key
+}
+
+//
+// Return the number of items
+//
+func (d *OrderedDict) Len() int {
+	return len(d.entries) - d.deleted
+}
+
+//
+// Return the value for key and true if present, nil and false otherwise
+//
+func (d *OrderedDict) Lookup(key Any) (Any, bool) {
+	if i, ok := d.index[hashKey(key)]; ok {
+		return d.entries[i].value, true
+	}
+
+	return nil, false
+}
+
+//
+// Return true if key is in the dictionary
+//
+func (d *OrderedDict) Contains(key Any) bool {
+	_, ok := d.index[hashKey(key)]
+	return ok
+}
+
+//
+// d[key] (panics with KeyError if key is not present)
+//
+func (d *OrderedDict) Item(key Any) Any {
+	if v, ok := d.Lookup(key); ok {
+		return v
+	}
+
+	panic(fmt.Sprintf("KeyError: %v", Repr(key)))
+}
+
+//
+// d.get(key[, default]): the value for key if present, default (or None) otherwise
+//
+func (d *OrderedDict) Get(key Any, def ...Any) Any {
+	if v, ok := d.Lookup(key); ok {
+		return v
+	}
+
+	if len(def) > 0 {
+		return def[0]
+	}
+
+	return nil
+}
+
+//
+// d[key] = value
+//
+func (d *OrderedDict) Set(key, value Any) {
+	if d.index == nil {
+		d.index = map[Any]int{}
+	}
+
+	h := hashKey(key)
+	if i, ok := d.index[h]; ok {
+		d.entries[i].value = value
+		return
+	}
+
+	d.index[h] = len(d.entries)
+	d.entries = append(d.entries, dictEntry{key: key, value: value})
+}
+
+//
+// del d[key] (panics with KeyError if key is not present)
+//
+func (d *OrderedDict) Delete(key Any) {
+	if _, ok := d.remove(key); !ok {
+		panic(fmt.Sprintf("KeyError: %v", Repr(key)))
+	}
+}
+
+//
+// remove key, returning its value and true if it was present
+//
+func (d *OrderedDict) remove(key Any) (Any, bool) {
+	h := hashKey(key)
+	i, ok := d.index[h]
+	if !ok {
+		return nil, false
+	}
+
+	v := d.entries[i].value
+	delete(d.index, h)
+	d.entries[i] = dictEntry{deleted: true}
+	d.deleted++
+
+	if d.deleted > 16 && d.deleted > len(d.entries)/2 {
+		d.compact()
+	}
+
+	return v, true
+}
+
+//
+// remove the deleted entries
+//
+func (d *OrderedDict) compact() {
+	entries := make([]dictEntry, 0, d.Len())
+	for _, e := range d.entries {
+		if !e.deleted {
+			d.index[hashKey(e.key)] = len(entries)
+			entries = append(entries, e)
+		}
+	}
+
+	d.entries = entries
+	d.deleted = 0
+}
+
+//
+// d.setdefault(key[, default]): the value for key if present,
+// otherwise set key to default (or None) and return it
+//
+func (d *OrderedDict) SetDefault(key Any, def ...Any) Any {
+	if v, ok := d.Lookup(key); ok {
+		return v
+	}
+
+	var v Any
+	if len(def) > 0 {
+		v = def[0]
+	}
+
+	d.Set(key, v)
+	return v
+}
+
+//
+// d.pop(key[, default]): remove key and return its value.
+// If key is not present return default, or panic with KeyError if there is no default
+//
+func (d *OrderedDict) Pop(key Any, def ...Any) Any {
+	if v, ok := d.remove(key); ok {
+		return v
+	}
+
+	if len(def) > 0 {
+		return def[0]
+	}
+
+	panic(fmt.Sprintf("KeyError: %v", Repr(key)))
+}
+
+//
+// d.popitem(): remove and return the last inserted (key, value) pair
+//
+func (d *OrderedDict) PopItem() Tuple {
+	for i := len(d.entries) - 1; i >= 0; i-- {
+		if e := d.entries[i]; !e.deleted {
+			d.remove(e.key)
+			return Tuple{e.key, e.value}
+		}
+	}
+
+	panic("KeyError: 'popitem(): dictionary is empty'")
+}
+
+//
+// d.update(other): add the items of other (a mapping or an iterable of key, value pairs).
+// A Dict is a Go map, that has no order: its keys are added sorted, to get a predictable order
+//
+func (d *OrderedDict) Update(other Any) {
+	switch o := other.(type) {
+	case nil:
+
+	case *OrderedDict:
+		for k, v := range o.All() {
+			d.Set(k, v)
+		}
+
+	case Dict:
+		for _, k := range slices.Sorted(maps.Keys(o)) {
+			d.Set(k, o[k])
+		}
+
+	default:
+		for item := range Iterate(other) {
+			kv, ok := item.(Tuple)
+			if !ok {
+				kv = ListFrom(item)
+			}
+			if len(kv) != 2 {
+				panic("ValueError: dictionary update sequence element has wrong length")
+			}
+			d.Set(kv[0], kv[1])
+		}
+	}
+}
+
+//
+// d | other: a new dictionary with the items of d updated with the items of other
+//
+func (d *OrderedDict) Merge(other Any) *OrderedDict {
+	m := d.Copy()
+	m.Update(other)
+	return m
+}
+
+//
+// Remove all items
+//
+func (d *OrderedDict) Clear() {
+	d.entries = nil
+	d.index = map[Any]int{}
+	d.deleted = 0
+}
+
+//
+// Return a shallow copy
+//
+func (d *OrderedDict) Copy() *OrderedDict {
+	c := NewOrderedDict()
+	for k, v := range d.All() {
+		c.Set(k, v)
+	}
+
+	return c
+}
+
+//
+// Iterate over the (key, value) pairs, in insertion order.
+// As in python, the dictionary can't change size during iteration
+//
+func (d *OrderedDict) All() iter.Seq2[Any, Any] {
+	return func(yield func(Any, Any) bool) {
+		n := d.Len()
+
+		for i := 0; i < len(d.entries); i++ {
+			e := d.entries[i]
+			if e.deleted {
+				continue
+			}
+
+			if !yield(e.key, e.value) {
+				return
+			}
+
+			if d.Len() != n {
+				panic("RuntimeError: dictionary changed size during iteration")
+			}
+		}
+	}
+}
+
+//
+// d.keys()
+//
+func (d *OrderedDict) Keys() List {
+	keys := make(List, 0, d.Len())
+	for k := range d.All() {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+//
+// d.values()
+//
+func (d *OrderedDict) Values() List {
+	values := make(List, 0, d.Len())
+	for _, v := range d.All() {
+		values = append(values, v)
+	}
+
+	return values
+}
+
+//
+// d.items(), as a list of (key, value) tuples
+//
+func (d *OrderedDict) Items() List {
+	items := make(List, 0, d.Len())
+	for k, v := range d.All() {
+		items = append(items, Tuple{k, v})
+	}
+
+	return items
+}
+
+//
+// Convert to a Dict, to pass as **kwargs (the keys must be strings)
+//
+func (d *OrderedDict) ToDict() Dict {
+	m := make(Dict, d.Len())
+	for k, v := range d.All() {
+		s, ok := k.(string)
+		if !ok {
+			panic("TypeError: keywords must be strings")
+		}
+		m[s] = v
+	}
+
+	return m
+}
+
+//
+// d == other (same keys and values, in any order)
+//
+func (d *OrderedDict) Equal(other *OrderedDict) bool {
+	if d.Len() != other.Len() {
+		return false
+	}
+
+	for k, v := range d.All() {
+		if w, ok := other.Lookup(k); !ok || !Eq(v, w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+//
+// Format the dictionary as python does
+//
+func (d *OrderedDict) String() string {
+	items := make([]string, 0, d.Len())
+	for k, v := range d.All() {
+		items = append(items, Repr(k)+": "+Repr(v))
+	}
+
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+//
+// k in m, for a Go map
+//
+func MapContains[K comparable, V any](m map[K]V, k K) bool {
+	_, ok := m[k]
+	return ok
+}
+
+//
+// m.get(k), for a Go map: the value, or nil (None) if k is not present
+//
+func MapLookup[K comparable, V any](m map[K]V, k K) Any {
+	if v, ok := m[k]; ok {
+		return v
+	}
+
+	return nil
+}
+
+//
+// m.get(k, default), for a Go map
+//
+func MapGet[K comparable, V any](m map[K]V, k K, def V) V {
+	if v, ok := m[k]; ok {
+		return v
+	}
+
+	return def
+}
+
+//
+// m.setdefault(k, default), for a Go map
+//
+func MapSetDefault[K comparable, V any](m map[K]V, k K, def V) V {
+	if v, ok := m[k]; ok {
+		return v
+	}
+
+	m[k] = def
+	return def
+}
+
+//
+// m.pop(k[, default]), for a Go map: panic with KeyError if k is not present and there is no default
+//
+func MapPop[K comparable, V any](m map[K]V, k K, def ...V) V {
+	if v, ok := m[k]; ok {
+		delete(m, k)
+		return v
+	}
+
+	if len(def) > 0 {
+		return def[0]
+	}
+
+	panic(fmt.Sprintf("KeyError: %v", Repr(k)))
+}
+
+//
+// m.popitem(), for a Go map: since Go maps are not ordered, this is an arbitrary item
+//
+func MapPopItem[K comparable, V any](m map[K]V) Tuple {
+	for k, v := range m {
+		delete(m, k)
+		return Tuple{k, v}
+	}
+
+	panic("KeyError: 'popitem(): dictionary is empty'")
+}
+
+//
+// m.update(other), for a Go map: the items of other (a Go map or a dict, as returned by All)
+// are converted to the key and value types of m. Return m
+//
+func MapUpdate[K comparable, V any, K2 comparable, V2 any](m map[K]V, items iter.Seq2[K2, V2]) map[K]V {
+	for k, v := range items {
+		m[any(k).(K)] = any(v).(V)
+	}
+
+	return m
+}
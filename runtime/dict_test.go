@@ -0,0 +1,199 @@
+package runtime
+
+import "iter"
+import "maps"
+import "slices"
+import "testing"
+
+func TestOrderedDictOrder(t *testing.T) {
+	d := NewOrderedDict("b", 1, 2, "two", Tuple{1, "x"}, 3.5)
+	d.Set("a", true)
+	d.Set("b", 10)
+
+	if s := d.String(); s != "{'b': 10, 2: 'two', [1, 'x']: 3.5, 'a': True}" {
+		t.Error("unexpected dictionary", s)
+	}
+
+	if !d.Contains(2.0) || !d.Contains(Tuple{1, "x"}) || d.Contains("c") {
+		t.Error("Contains failed")
+	}
+
+	d.Delete("b")
+	if k := d.Keys(); !Eq(k, List{2, Tuple{1, "x"}, "a"}) {
+		t.Error("unexpected keys after delete", k)
+	}
+
+	if kv := d.PopItem(); !Eq(kv, Tuple{"a", true}) {
+		t.Error("popitem should return the last item, got", kv)
+	}
+}
+
+func TestOrderedDictTupleKeys(t *testing.T) {
+	d := NewOrderedDict(Tuple{"a,string:b"}, 1, Tuple{"a", "b"}, 2, Tuple{Tuple{"a"}, "b"}, 3, Tuple{1, 2}, 4)
+	d.Set(Tuple{1.0, true, 2}, 5)
+
+	if d.Len() != 5 {
+		t.Error("the tuple keys should be different, got", d)
+	}
+
+	if v := d.Item(Tuple{"a", "b"}); v != 2 {
+		t.Error(`d[("a", "b")] should be 2, got`, v)
+	}
+
+	if d.Set(Tuple{1.0, 2}, 6); d.Len() != 5 || d.Item(Tuple{1, 2}) != 6 {
+		t.Error("(1.0, 2) should be the same key as (1, 2), got", d)
+	}
+}
+
+func TestOrderedDictMethods(t *testing.T) {
+	d := NewOrderedDict()
+
+	if v := d.Get("x"); v != nil {
+		t.Error("get of a missing key should return None, got", v)
+	}
+
+	if v := d.Get("x", 1); v != 1 {
+		t.Error("get with default should return 1, got", v)
+	}
+
+	if v := d.SetDefault("x", List{}); !Eq(v, List{}) || !d.Contains("x") {
+		t.Error("setdefault should set the missing key")
+	}
+
+	if v := d.Pop("y", 0); v != 0 {
+		t.Error("pop with default should return 0, got", v)
+	}
+
+	d.Update(Dict{"z": 26})
+	m := d.Merge(NewOrderedDict("x", 1))
+
+	if !m.Equal(NewOrderedDict("z", 26, "x", 1)) || !Eq(d.Get("x"), List{}) {
+		t.Error("unexpected merge result", m, d)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("d[missing] should raise KeyError")
+		}
+	}()
+
+	d.Item("missing")
+}
+
+func TestOrderedDictIteration(t *testing.T) {
+	d := NewOrderedDict()
+	for i := 0; i < 100; i++ {
+		d.Set(i, i*i)
+	}
+	for i := 0; i < 100; i += 2 {
+		d.Delete(i)
+	}
+
+	n := 1
+	for k, v := range d.All() {
+		if k != n || v != n*n {
+			t.Fatal("unexpected item", k, v)
+		}
+		n += 2
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("changing the dictionary size while iterating should panic")
+		}
+	}()
+
+	for k := range d.All() {
+		d.Delete(k)
+	}
+}
+
+func TestMapMethods(t *testing.T) {
+	m := map[string]int{"a": 1}
+
+	if !MapContains(m, "a") || MapContains(m, "b") {
+		t.Error("m should contain only a")
+	}
+
+	if v := MapLookup(m, "b"); v != nil {
+		t.Error("get of a missing key should return None, got", v)
+	}
+
+	if v := MapLookup(m, "a"); v != 1 {
+		t.Error("get of a should return 1, got", v)
+	}
+
+	if v := MapGet(m, "b", 2); v != 2 || len(m) != 1 {
+		t.Error("get of a missing key should return the default, got", v)
+	}
+
+	if v := MapSetDefault(m, "b", 2); v != 2 || m["b"] != 2 {
+		t.Error("setdefault of a missing key should set the default, got", v)
+	}
+
+	if v := MapPop(m, "b"); v != 2 || MapContains(m, "b") {
+		t.Error("pop should remove b and return 2, got", v)
+	}
+
+	if v := MapPop(m, "b", 0); v != 0 {
+		t.Error("pop of a missing key should return the default, got", v)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "KeyError: 'b'" {
+				t.Error("pop of a missing key without default should raise KeyError, got", r)
+			}
+		}()
+		MapPop(m, "b")
+	}()
+
+	MapUpdate(m, NewOrderedDict("c", 3).All())
+	if m["c"] != 3 {
+		t.Error("update should add c from the dict, got", m)
+	}
+
+	d := Dict{}
+	MapUpdate(d, maps.All(m))
+	if len(d) != 2 || d["a"] != 1 {
+		t.Error("update should convert the values, got", d)
+	}
+
+	if item := MapPopItem(map[string]int{"x": 1}); item[0] != "x" || item[1] != 1 {
+		t.Error("popitem should return (x, 1), got", item)
+	}
+}
+
+func TestOrderedDictUpdateIterable(t *testing.T) {
+	pairs := func(yield func(Any) bool) {
+		_ = yield(Tuple{"a", 1}) && yield(List{"b", 2})
+	}
+
+	d := DictFrom(iter.Seq[Any](pairs))
+	if !Eq(d.Keys(), List{"a", "b"}) || d.Item("b") != 2 {
+		t.Error("dict(generator) should have the keys a and b, got", d)
+	}
+
+	d = DictFrom(NewGenerator(slices.Values([]Tuple{{"c", 3}})))
+	if d.Item("c") != 3 {
+		t.Error("dict(generator) should have the key c, got", d)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "ValueError: dictionary update sequence element has wrong length" {
+				t.Error("an element that is not a pair should raise ValueError, got", r)
+			}
+		}()
+		DictFrom(List{Tuple{1, 2, 3}})
+	}()
+}
+
+func TestOrderedDictUpdateDict(t *testing.T) {
+	for range 10 {
+		d := DictFrom(Dict{"c": 3, "a": 1, "b": 2, "e": 5, "d": 4})
+		if !Eq(d.Keys(), List{"a", "b", "c", "d", "e"}) {
+			t.Fatal("the keys of a Dict should be added sorted, got", d.Keys())
+		}
+	}
+}
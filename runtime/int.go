@@ -0,0 +1,344 @@
+package runtime
+
+import "fmt"
+import "math"
+import "math/big"
+import "math/bits"
+
+//
+// Int is a python int: an arbitrary precision integer.
+// Values that fit an int64 are stored as such (and operations on them stay on the fast path),
+// larger values use a big.Int (the zero Int is 0)
+//
+type Int struct {
+	small int64
+	big   *big.Int // if not nil this is the value, and small is ignored
+}
+
+//
+// Return an Int with value n
+//
+func NewInt(n int) Int {
+	return Int{small: int64(n)}
+}
+
+//
+// Return the Int represented by the decimal string s
+//
+func ParseInt(s string) (Int, error) {
+	b, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Int{}, fmt.Errorf("ValueError: invalid literal for int() with base 10: %q", s)
+	}
+
+	return fromBig(b), nil
+}
+
+//
+// Like ParseInt, but panics on errors (for literals)
+//
+func MustParseInt(s string) Int {
+	i, err := ParseInt(s)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return i
+}
+
+//
+// Return an Int from a big.Int, using the small representation if it fits
+//
+func fromBig(b *big.Int) Int {
+	if b.IsInt64() {
+		return Int{small: b.Int64()}
+	}
+
+	return Int{big: b}
+}
+
+//
+// Return the value of i as a big.Int (a copy, safe to modify)
+//
+func (i Int) Big() *big.Int {
+	if i.big != nil {
+		return new(big.Int).Set(i.big)
+	}
+
+	return big.NewInt(i.small)
+}
+
+//
+// Return true if the value of i fits an int64
+//
+func (i Int) IsSmall() bool {
+	return i.big == nil
+}
+
+//
+// Return the value of i as a Go int (panics if it doesn't fit)
+//
+func (i Int) Int() int {
+	if i.big != nil || i.small != int64(int(i.small)) {
+		panic("OverflowError: Python int too large to convert to Go int")
+	}
+
+	return int(i.small)
+}
+
+//
+// Return the value of i as a float64
+//
+func (i Int) Float64() float64 {
+	if i.big != nil {
+		f, _ := new(big.Float).SetInt(i.big).Float64()
+		return f
+	}
+
+	return float64(i.small)
+}
+
+//
+// Python truth value (true if not zero)
+//
+func (i Int) Bool() bool {
+	return i.big != nil || i.small != 0
+}
+
+func (i Int) String() string {
+	if i.big != nil {
+		return i.big.String()
+	}
+
+	return fmt.Sprint(i.small)
+}
+
+//
+// Compare i and j, returning -1, 0 or 1
+//
+func (i Int) Cmp(j Int) int {
+	if i.big == nil && j.big == nil {
+		switch {
+		case i.small < j.small:
+			return -1
+		case i.small > j.small:
+			return 1
+		}
+		return 0
+	}
+
+	return i.Big().Cmp(j.Big())
+}
+
+//
+// i + j
+//
+func (i Int) Add(j Int) Int {
+	if i.big == nil && j.big == nil {
+		if r := i.small + j.small; (r > i.small) == (j.small > 0) {
+			return Int{small: r}
+		}
+	}
+
+	return fromBig(new(big.Int).Add(i.Big(), j.Big()))
+}
+
+//
+// i - j
+//
+func (i Int) Sub(j Int) Int {
+	if i.big == nil && j.big == nil {
+		if r := i.small - j.small; (r < i.small) == (j.small > 0) {
+			return Int{small: r}
+		}
+	}
+
+	return fromBig(new(big.Int).Sub(i.Big(), j.Big()))
+}
+
+//
+// i * j
+//
+func (i Int) Mul(j Int) Int {
+	if i.big == nil && j.big == nil && i.small != math.MinInt64 && j.small != math.MinInt64 {
+		a, b := abs64(i.small), abs64(j.small)
+		if hi, lo := bits.Mul64(a, b); hi == 0 && lo <= math.MaxInt64 {
+			if (i.small < 0) != (j.small < 0) {
+				return Int{small: -int64(lo)}
+			}
+			return Int{small: int64(lo)}
+		}
+	}
+
+	return fromBig(new(big.Int).Mul(i.Big(), j.Big()))
+}
+
+func abs64(n int64) uint64 {
+	if n < 0 {
+		return uint64(-n)
+	}
+
+	return uint64(n)
+}
+
+//
+// i // j (rounding toward negative infinity)
+//
+func (i Int) FloorDiv(j Int) Int {
+	if !j.Bool() {
+		panic(zeroDivision())
+	}
+
+	if i.big == nil && j.big == nil && !(i.small == math.MinInt64 && j.small == -1) {
+		return Int{small: int64(FloorDivInt(int(i.small), int(j.small)))}
+	}
+
+	q, m := new(big.Int).DivMod(i.Big(), j.Big(), new(big.Int))
+	if m.Sign() != 0 && j.Sign() < 0 { // DivMod uses euclidean division
+		q.Sub(q, big.NewInt(1))
+	}
+
+	return fromBig(q)
+}
+
+//
+// i % j (with the sign of j)
+//
+func (i Int) Mod(j Int) Int {
+	if !j.Bool() {
+		panic(zeroDivision())
+	}
+
+	if i.big == nil && j.big == nil && !(i.small == math.MinInt64 && j.small == -1) {
+		return Int{small: int64(ModInt(int(i.small), int(j.small)))}
+	}
+
+	m := new(big.Int).Mod(i.Big(), j.Big()) // euclidean modulo, always >= 0
+	if m.Sign() != 0 && j.Sign() < 0 {
+		m.Add(m, j.Big())
+	}
+
+	return fromBig(m)
+}
+
+//
+// i / j (a float)
+//
+func (i Int) TrueDiv(j Int) float64 {
+	if !j.Bool() {
+		panic(zeroDivision())
+	}
+
+	if i.big == nil && j.big == nil {
+		return float64(i.small) / float64(j.small)
+	}
+
+	f, _ := new(big.Rat).SetFrac(i.Big(), j.Big()).Float64()
+	return f
+}
+
+//
+// i ** j (panics for negative exponents, since the result would be a float)
+//
+func (i Int) Pow(j Int) Int {
+	if j.Sign() < 0 {
+		panic("ValueError: negative exponent in integer power")
+	}
+
+	return fromBig(new(big.Int).Exp(i.Big(), j.Big(), nil))
+}
+
+//
+// -i
+//
+func (i Int) Neg() Int {
+	if i.big == nil && i.small != math.MinInt64 {
+		return Int{small: -i.small}
+	}
+
+	return fromBig(new(big.Int).Neg(i.Big()))
+}
+
+//
+// abs(i)
+//
+func (i Int) Abs() Int {
+	if i.Sign() < 0 {
+		return i.Neg()
+	}
+
+	return i
+}
+
+//
+// Return -1, 0 or 1 depending on the sign of i
+//
+func (i Int) Sign() int {
+	if i.big != nil {
+		return i.big.Sign()
+	}
+
+	switch {
+	case i.small < 0:
+		return -1
+	case i.small > 0:
+		return 1
+	}
+
+	return 0
+}
+
+//
+// i & j
+//
+func (i Int) And(j Int) Int {
+	if i.big == nil && j.big == nil {
+		return Int{small: i.small & j.small}
+	}
+
+	return fromBig(new(big.Int).And(i.Big(), j.Big()))
+}
+
+//
+// i | j
+//
+func (i Int) Or(j Int) Int {
+	if i.big == nil && j.big == nil {
+		return Int{small: i.small | j.small}
+	}
+
+	return fromBig(new(big.Int).Or(i.Big(), j.Big()))
+}
+
+//
+// i ^ j
+//
+func (i Int) Xor(j Int) Int {
+	if i.big == nil && j.big == nil {
+		return Int{small: i.small ^ j.small}
+	}
+
+	return fromBig(new(big.Int).Xor(i.Big(), j.Big()))
+}
+
+//
+// i << j
+//
+func (i Int) Lsh(j Int) Int {
+	if j.Sign() < 0 {
+		panic("ValueError: negative shift count")
+	}
+
+	return fromBig(new(big.Int).Lsh(i.Big(), uint(j.Int())))
+}
+
+//
+// i >> j
+//
+func (i Int) Rsh(j Int) Int {
+	if j.Sign() < 0 {
+		panic("ValueError: negative shift count")
+	}
+
+	return fromBig(new(big.Int).Rsh(i.Big(), uint(j.Int())))
+}
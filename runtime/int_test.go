@@ -0,0 +1,66 @@
+package runtime
+
+import "math"
+import "testing"
+
+func TestIntOverflow(t *testing.T) {
+	max := NewInt(math.MaxInt64)
+
+	if v := max.Add(NewInt(1)).String(); v != "9223372036854775808" {
+		t.Error("MaxInt64 + 1 should be 9223372036854775808, got", v)
+	}
+
+	if v := NewInt(math.MinInt64).Sub(NewInt(1)).String(); v != "-9223372036854775809" {
+		t.Error("MinInt64 - 1 should be -9223372036854775809, got", v)
+	}
+
+	if v := max.Mul(max).Sub(max.Mul(max)); !v.IsSmall() || v.Bool() {
+		t.Error("x*x - x*x should be a small 0, got", v)
+	}
+
+	if v := NewInt(2).Pow(NewInt(100)).String(); v != "1267650600228229401496703205376" {
+		t.Error("2 ** 100 should be 1267650600228229401496703205376, got", v)
+	}
+}
+
+func TestIntDivision(t *testing.T) {
+	big := MustParseInt("100000000000000000000")
+
+	if v := big.Neg().FloorDiv(NewInt(3)).String(); v != "-33333333333333333334" {
+		t.Error("-10**20 // 3 should be -33333333333333333334, got", v)
+	}
+
+	if v := big.FloorDiv(NewInt(-3)).String(); v != "-33333333333333333334" {
+		t.Error("10**20 // -3 should be -33333333333333333334, got", v)
+	}
+
+	if v := big.Mod(NewInt(-3)).String(); v != "-2" {
+		t.Error("10**20 % -3 should be -2, got", v)
+	}
+
+	if v := NewInt(-7).FloorDiv(NewInt(2)); v.Cmp(NewInt(-4)) != 0 {
+		t.Error("-7 // 2 should be -4, got", v)
+	}
+
+	if v := NewInt(7).TrueDiv(NewInt(2)); v != 3.5 {
+		t.Error("7 / 2 should be 3.5, got", v)
+	}
+}
+
+func TestIntDynamic(t *testing.T) {
+	if v := Add(NewInt(1), 2); !Eq(v, 3) {
+		t.Error("Int(1) + 2 should be 3, got", v)
+	}
+
+	if v := Mul(NewInt(math.MaxInt64), 2); v.(Int).String() != "18446744073709551614" {
+		t.Error("MaxInt64 * 2 should be 18446744073709551614, got", v)
+	}
+
+	if !Lt(NewInt(1), 1.5) || !Truthy(NewInt(1)) || Truthy(NewInt(0)) {
+		t.Error("Int comparison or truth value failed")
+	}
+
+	if _, err := ParseInt("12x"); err == nil {
+		t.Error("ParseInt(\"12x\") should fail")
+	}
+}
@@ -0,0 +1,373 @@
+package runtime
+
+import "bufio"
+import "fmt"
+import "io"
+import "iter"
+import "reflect"
+import goruntime "runtime"
+import "slices"
+
+//
+// An Iterator returns the elements of an iterable, one at a time
+//
+type Iterator interface {
+	//
+	// Return the next element and true, or nil and false when there are no more elements
+	//
+	Next() (Any, bool)
+}
+
+type listIterator struct {
+	l List
+	i int
+}
+
+func (it *listIterator) Next() (Any, bool) {
+	if it.i >= len(it.l) {
+		return nil, false
+	}
+
+	it.i++
+	return it.l[it.i-1], true
+}
+
+//
+// An Iterator over an iter.Seq, returning the elements as they are produced.
+// The sequence is stopped when it's exhausted, or when the iterator is garbage collected
+// (a sequence that was not consumed would otherwise keep its coroutine)
+//
+type seqIterator struct {
+	next func() (Any, bool)
+	stop func()
+}
+
+func newSeqIterator(seq iter.Seq[Any]) *seqIterator {
+	next, stop := iter.Pull(seq)
+	it := &seqIterator{next: next, stop: stop}
+	goruntime.SetFinalizer(it, (*seqIterator).close)
+	return it
+}
+
+func (it *seqIterator) Next() (Any, bool) {
+	v, ok := it.next()
+	if !ok {
+		it.close()
+	}
+
+	goruntime.KeepAlive(it) // not stopped while producing the element
+	return v, ok
+}
+
+func (it *seqIterator) close() {
+	it.stop() // stop can be called more than once
+}
+
+//
+// A Generator is a generator expression: a single-use Iterator over an iter.Seq,
+// producing the elements as they are requested, by Next or by ranging over All.
+// As seqIterator, the sequence is stopped when it's exhausted or when the generator is garbage collected
+//
+type Generator[T any] struct {
+	seq  iter.Seq[T]
+	next func() (T, bool)
+	stop func()
+}
+
+//
+// Return a Generator producing the elements of seq
+//
+func NewGenerator[T any](seq iter.Seq[T]) *Generator[T] {
+	return &Generator[T]{seq: seq}
+}
+
+//
+// Return the next element and true, or the zero value and false when there are no more elements
+//
+func (g *Generator[T]) NextOf() (T, bool) {
+	if g.next == nil { // the sequence is started by the first request
+		g.next, g.stop = iter.Pull(g.seq)
+		goruntime.SetFinalizer(g, (*Generator[T]).close)
+	}
+
+	v, ok := g.next()
+	if !ok {
+		g.close()
+	}
+
+	goruntime.KeepAlive(g)
+	return v, ok
+}
+
+//
+// Implement Iterator
+//
+func (g *Generator[T]) Next() (Any, bool) {
+	return g.NextOf()
+}
+
+//
+// The remaining elements (for range): as in python, the elements are produced only once
+//
+func (g *Generator[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, ok := g.NextOf()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (g *Generator[T]) close() {
+	if g.stop != nil {
+		g.stop()
+	}
+}
+
+//
+// iter(v): an Iterator over the elements of v (v itself if it is an Iterator).
+// The elements are produced lazily, as Iterate does
+//
+func Iter(v Any) Iterator {
+	switch c := v.(type) {
+	case Iterator:
+		return c
+
+	case List: // or Tuple
+		return &listIterator{l: c}
+	}
+
+	return newSeqIterator(Iterate(v))
+}
+
+//
+// next(it[, default]): the next element of the iterator it.
+// If there are no more elements return default, or panic with StopIteration if there is no default.
+// An iter.Seq is accepted, but it has no state: it is started again by each call (use a Generator)
+//
+func Next(it Any, def ...Any) Any {
+	iter, ok := it.(Iterator)
+	if !ok && isSeq(it) {
+		iter, ok = newSeqIterator(Iterate(it)), true
+	}
+	if !ok {
+		panic(fmt.Sprintf("TypeError: '%v' object is not an iterator", typeName(it)))
+	}
+
+	if v, ok := iter.Next(); ok {
+		return v
+	}
+
+	if len(def) > 0 {
+		return def[0]
+	}
+
+	panic("StopIteration")
+}
+
+//
+// Iterate over the elements of v, as `for x in v` does, producing them one at a time:
+// lists, tuples and Go slices, strings (by character), sets, dicts (their keys), ranges,
+// channels (generators), iter.Seq, files and readers (by line, with the newline),
+// Iterators (as Generators) and objects implementing Iter() Any (__iter__)
+//
+func Iterate(v Any) iter.Seq[Any] {
+	switch c := v.(type) {
+	case List: // or Tuple
+		return slices.Values(c)
+
+	case iter.Seq[Any]:
+		return c
+
+	case string:
+		return func(yield func(Any) bool) {
+			for s := range Chars(c) {
+				if !yield(s) {
+					return
+				}
+			}
+		}
+
+	case Set:
+		return func(yield func(Any) bool) {
+			for _, v := range c {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+
+	case *OrderedDict:
+		return func(yield func(Any) bool) {
+			for k := range c.All() {
+				if !yield(k) {
+					return
+				}
+			}
+		}
+
+	case Range:
+		return func(yield func(Any) bool) {
+			for i := range c.All() {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+
+	case Iterator:
+		return func(yield func(Any) bool) {
+			for {
+				x, ok := c.Next()
+				if !ok || !yield(x) {
+					return
+				}
+			}
+		}
+
+	case interface{ Iter() Any }: // __iter__
+		return Iterate(c.Iter())
+
+	case io.Reader:
+		return func(yield func(Any) bool) {
+			r := bufio.NewReader(c)
+			for {
+				line, err := r.ReadString('\n')
+				if line != "" && !yield(line) {
+					return
+				}
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Slice, reflect.Array: // typed lists
+		return func(yield func(Any) bool) {
+			for i := 0; i < rv.Len(); i++ {
+				if !yield(rv.Index(i).Interface()) {
+					return
+				}
+			}
+		}
+
+	case reflect.Map: // typed dicts and sets, Dict
+		return func(yield func(Any) bool) {
+			for it := rv.MapRange(); it.Next(); {
+				if !yield(it.Key().Interface()) {
+					return
+				}
+			}
+		}
+
+	case reflect.Chan: // generators
+		return func(yield func(Any) bool) {
+			for {
+				x, ok := rv.Recv()
+				if !ok || !yield(x.Interface()) {
+					return
+				}
+			}
+		}
+
+	case reflect.Func: // typed sequences (iter.Seq[T])
+		if t := rv.Type(); isSeq(v) {
+			return func(yield func(Any) bool) {
+				rv.Call([]reflect.Value{reflect.MakeFunc(t.In(0), func(args []reflect.Value) []reflect.Value {
+					return []reflect.Value{reflect.ValueOf(yield(args[0].Interface()))}
+				})})
+			}
+		}
+	}
+
+	panic(fmt.Sprintf("TypeError: '%v' object is not iterable", typeName(v)))
+}
+
+//
+// v is an iter.Seq[T]: a func(yield func(T) bool)
+//
+func isSeq(v Any) bool {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return false
+	}
+
+	y := t.In(0)
+	return y.Kind() == reflect.Func && y.NumIn() == 1 && y.NumOut() == 1 && y.Out(0).Kind() == reflect.Bool
+}
+
+//
+// Iterate over the characters of s (as one character strings)
+//
+func Chars(s string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for _, r := range s {
+			if !yield(string(r)) {
+				return
+			}
+		}
+	}
+}
+
+//
+// Iterate over the elements of v, unpacking each element into a tuple of n values
+// (as in `for a, b in v`). Panics with ValueError if an element doesn't have n values
+//
+func Unpacked(v Any, n int) iter.Seq[Tuple] {
+	return func(yield func(Tuple) bool) {
+		for x := range Iterate(v) {
+			if !yield(Unpack(x, n)) {
+				return
+			}
+		}
+	}
+}
+
+//
+// Unpack the elements of the iterable v into a tuple of n values
+// (panics with ValueError if v doesn't have n elements)
+//
+func Unpack(v Any, n int) Tuple {
+	t, ok := v.(Tuple)
+	if !ok {
+		t = ListFrom(v)
+	}
+
+	switch {
+	case len(t) < n:
+		panic(fmt.Sprintf("ValueError: not enough values to unpack (expected %v, got %v)", n, len(t)))
+	case len(t) > n:
+		panic(fmt.Sprintf("ValueError: too many values to unpack (expected %v)", n))
+	}
+
+	return t
+}
+
+//
+// Unpack the elements of the iterable v for a target with a starred element, as `a, *b, c = v`:
+// the tuple has the first before elements, a List with the elements in the middle and the last after elements
+// (panics with ValueError if v has less than before+after elements)
+//
+func UnpackStarred(v Any, before, after int) Tuple {
+	l := ListFrom(v)
+	if len(l) < before+after {
+		panic(fmt.Sprintf("ValueError: not enough values to unpack (expected at least %v, got %v)", before+after, len(l)))
+	}
+
+	t := make(Tuple, 0, before+after+1)
+	t = append(t, l[:before]...)
+	t = append(t, slices.Clone(l[before:len(l)-after]))
+	return append(t, l[len(l)-after:]...)
+}
+
+//
+// Pack the values returned by a function with multiple results into a tuple,
+// as `runtime.Pack(f())`, when the result of the call is used as a single value
+//
+func Pack(values ...Any) Tuple {
+	return Tuple(values)
+}
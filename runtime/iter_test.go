@@ -0,0 +1,182 @@
+package runtime
+
+import "iter"
+import goruntime "runtime"
+import "slices"
+import "strings"
+import "testing"
+import "time"
+
+type countdown int
+
+func (c countdown) Iter() Any {
+	return NewRange(int(c), 0, -1)
+}
+
+func TestIterate(t *testing.T) {
+	ch := make(chan Any)
+	go func() {
+		for _, v := range []string{"x", "y"} {
+			ch <- v
+		}
+		close(ch)
+	}()
+
+	tests := []struct {
+		v    Any
+		want List
+	}{
+		{"héllo", List{"h", "é", "l", "l", "o"}},
+		{[]int{1, 2}, List{1, 2}},
+		{NewOrderedDict("b", 1, "a", 2), List{"b", "a"}},
+		{NewRange(1, 7, 3), List{1, 4}},
+		{ch, List{"x", "y"}},
+		{strings.NewReader("one\ntwo"), List{"one\n", "two"}},
+		{countdown(3), List{3, 2, 1}},
+		{Iter(List{5, 6}), List{5, 6}},
+		{iter.Seq[Any](slices.Values(List{"a", 1})), List{"a", 1}},
+		{slices.Values([]int{7, 8}), List{7, 8}},
+	}
+
+	for _, test := range tests {
+		if l := slices.Collect(Iterate(test.v)); !Eq(l, test.want) {
+			t.Errorf("iterating over %T should produce %v, got %v", test.v, test.want, l)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("iterating over an int should raise TypeError")
+		}
+	}()
+
+	Iterate(5)
+}
+
+func TestLazyIteration(t *testing.T) {
+	gen := make(chan Any)
+	go func() {
+		for i := 0; ; i++ {
+			gen <- i
+		}
+	}()
+
+	it := Iter(gen)
+	if Next(it) != 0 || Next(it) != 1 {
+		t.Error("next() should return the elements of the channel in order")
+	}
+
+	if v := Zip("ab", gen); !Eq(v, List{Tuple{"a", 2}, Tuple{"b", 3}}) {
+		t.Error("zip('ab', gen) failed, got", v)
+	}
+
+	if !AnyOf(gen) {
+		t.Error("any(gen) should be true")
+	}
+
+	// any() stops at the first true element (4)
+	if v := Next(Iter(gen)); v != 5 {
+		t.Error("the generator should not be consumed past what is needed, next is", v)
+	}
+}
+
+func TestIterateSeq(t *testing.T) {
+	// an endless generator expression (i for i in count()), that stops when the consumer does
+	naturals := iter.Seq[int](func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	})
+
+	if !AnyOf(naturals) {
+		t.Error("any(naturals) should be true")
+	}
+
+	if v := Zip("ab", naturals); !Eq(v, List{Tuple{"a", 0}, Tuple{"b", 1}}) {
+		t.Error("zip('ab', naturals) failed, got", v)
+	}
+
+	// next(iter(naturals)): the iterator is stopped when it's not used anymore
+	n := goruntime.NumGoroutine()
+	if v := Next(Iter(naturals)); v != 0 {
+		t.Error("next(iter(naturals)) should be 0, got", v)
+	}
+
+	for i := 0; i < 10 && goruntime.NumGoroutine() > n; i++ {
+		goruntime.GC()
+		time.Sleep(time.Millisecond)
+	}
+
+	if goruntime.NumGoroutine() > n {
+		t.Error("the iterator over naturals was not stopped")
+	}
+
+	if v := Next(naturals); v != 0 {
+		t.Error("next(naturals) should be 0, got", v)
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	// (i * i for i in range(5))
+	squares := NewGenerator(iter.Seq[int](func(yield func(int) bool) {
+		for i := range 5 {
+			if !yield(i * i) {
+				return
+			}
+		}
+	}))
+
+	if v := Next(squares); v != 0 {
+		t.Error("next(squares) should be 0, got", v)
+	}
+
+	// a loop continues where the previous one stopped
+	for v := range squares.All() {
+		if v != 1 {
+			t.Error("the loop should continue from 1, got", v)
+		}
+		break
+	}
+
+	if l := slices.Collect(squares.All()); !slices.Equal(l, []int{4, 9, 16}) {
+		t.Error("list(squares) should produce the remaining elements, got", l)
+	}
+
+	if l := ListFrom(squares); len(l) != 0 || Next(squares, "done") != "done" {
+		t.Error("the generator should be exhausted, got", l)
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	var pairs []string
+	for p := range Unpacked(List{Tuple{"a", 1}, "xy"}, 2) {
+		pairs = append(pairs, Str(p[0])+Str(p[1]))
+	}
+
+	if !slices.Equal(pairs, []string{"a1", "xy"}) {
+		t.Error("unpacking pairs failed, got", pairs)
+	}
+
+	if v := UnpackStarred("abcd", 1, 1); !Eq(v, Tuple{"a", List{"b", "c"}, "d"}) {
+		t.Error("a, *b, c = 'abcd' failed, got", v)
+	}
+
+	if v := UnpackStarred(List{1, 2}, 0, 2); !Eq(v, Tuple{List{}, 1, 2}) {
+		t.Error("*a, b, c = [1, 2] failed, got", v)
+	}
+
+	divmod := func(a, b int) (int, int) { return a / b, a % b }
+	if v := Pack(divmod(7, 2)); !Eq(v, Tuple{3, 1}) {
+		t.Error("packing the results of divmod(7, 2) failed, got", v)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("unpacking 3 values into 2 should raise ValueError")
+		}
+	}()
+
+	Unpack(List{1, 2, 3}, 2)
+}
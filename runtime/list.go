@@ -0,0 +1,180 @@
+package runtime
+
+import "fmt"
+import "slices"
+
+//
+// Return a new List with the elements of iterable
+// (a list, tuple, set, dict, range, string, Go slice or anything else Iterate accepts)
+//
+func ListFrom(iterable Any) List {
+	switch c := iterable.(type) {
+	case nil:
+		return List{}
+
+	case List:
+		return slices.Clone(c)
+
+	case Set:
+		return c.Items()
+
+	case *OrderedDict:
+		return c.Keys()
+
+	}
+
+	return slices.Collect(Iterate(iterable))
+}
+
+//
+// Compare a and b with python rules, returning -1, 0 or 1 (as cmp.Compare)
+//
+func Compare(a, b Any) int {
+	return compare("<", a, b)
+}
+
+//
+// l.index(v): the position of the first element equal to v
+// (panics with ValueError if v is not in the list)
+//
+func ListIndex(l List, v Any) int {
+	for i, e := range l {
+		if Eq(e, v) {
+			return i
+		}
+	}
+
+	panic(fmt.Sprintf("ValueError: %v is not in list", Repr(v)))
+}
+
+//
+// l.count(v): the number of elements equal to v
+//
+func ListCount(l List, v Any) int {
+	n := 0
+	for _, e := range l {
+		if Eq(e, v) {
+			n++
+		}
+	}
+
+	return n
+}
+
+//
+// l.remove(v): remove the first element equal to v, returning the updated list
+// (panics with ValueError if v is not in the list)
+//
+func ListRemove(l List, v Any) List {
+	for i, e := range l {
+		if Eq(e, v) {
+			return slices.Delete(l, i, i+1)
+		}
+	}
+
+	panic("ValueError: list.remove(x): x not in list")
+}
+
+//
+// l.pop([i]): remove and return the element at position i (the last one by default).
+// Negative positions count from the end of the list
+//
+func ListPop(l *List, i ...int) Any {
+	n := len(*l)
+	if n == 0 {
+		panic("IndexError: pop from empty list")
+	}
+
+	p := n - 1
+	if len(i) > 0 {
+		p = i[0]
+		if p < 0 {
+			p += n
+		}
+	}
+
+	if p < 0 || p >= n {
+		panic("IndexError: pop index out of range")
+	}
+
+	v := (*l)[p]
+	*l = slices.Delete(*l, p, p+1)
+	return v
+}
+
+//
+// l.insert(i, v): insert v before position i. Negative positions count from the end of the list,
+// and positions out of range insert at the start or at the end (python doesn't raise an IndexError)
+//
+func ListInsert[T any](l []T, i int, v T) []T {
+	n := len(l)
+	if i < 0 {
+		i = max(i+n, 0)
+	}
+
+	return slices.Insert(l, min(i, n), v)
+}
+
+//
+// del l[start:stop:step]: remove the elements selected by the extended slice.
+// start and stop can be nil (missing), and are adjusted as python does
+//
+func ListDeleteSlice[T any](l []T, start, stop Any, step int) []T {
+	if step == 0 {
+		panic("ValueError: slice step cannot be zero")
+	}
+
+	n := len(l)
+	bound := func(v Any, def int) int {
+		if v == nil {
+			return def
+		}
+
+		i := toInt(v)
+		switch {
+		case i < 0 && i+n < 0 && step < 0:
+			return -1
+		case i < 0 && i+n < 0:
+			return 0
+		case i < 0:
+			return i + n
+		case i >= n && step < 0:
+			return n - 1
+		case i >= n:
+			return n
+		}
+		return i
+	}
+
+	first, last := bound(start, 0), bound(stop, n)
+	if step < 0 {
+		first, last = bound(start, n-1), bound(stop, -1)
+	}
+
+	deleted := make([]bool, n)
+	for i := first; step > 0 && i < last || step < 0 && i > last; i += step {
+		deleted[i] = true
+	}
+
+	j := 0
+	for i, v := range l {
+		if !deleted[i] {
+			l[j] = v
+			j++
+		}
+	}
+
+	clear(l[j:])
+	return l[:j]
+}
+
+//
+// l.sort([reverse=True]): sort the list in place (the sort is stable, as in python)
+//
+func ListSort(l List, reverse bool) {
+	if reverse {
+		slices.SortStableFunc(l, func(a, b Any) int { return Compare(b, a) })
+	} else {
+		slices.SortStableFunc(l, Compare)
+	}
+}
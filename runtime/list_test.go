@@ -0,0 +1,90 @@
+package runtime
+
+import "slices"
+import "testing"
+
+func TestListMethods(t *testing.T) {
+	l := List{3, "a", 1.0, 3}
+
+	if i := ListIndex(l, 1); i != 2 {
+		t.Error("index(1) should be 2, got", i)
+	}
+
+	if n := ListCount(l, 3); n != 2 {
+		t.Error("count(3) should be 2, got", n)
+	}
+
+	l = ListRemove(l, 3)
+	if !Eq(l, List{"a", 1.0, 3}) {
+		t.Error("remove(3) should remove the first 3, got", l)
+	}
+
+	if v := ListPop(&l); v != 3 || len(l) != 2 {
+		t.Error("pop() should return 3 and leave 2 elements, got", v, l)
+	}
+
+	if v := ListPop(&l, -2); v != "a" || !Eq(l, List{1.0}) {
+		t.Error("pop(-2) should return 'a', got", v, l)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("remove(5) should raise ValueError")
+		}
+	}()
+
+	ListRemove(l, 5)
+}
+
+func TestListInsert(t *testing.T) {
+	l := []int{1, 2}
+
+	l = ListInsert(l, 10, 3)
+	l = ListInsert(l, -10, 0)
+	l = ListInsert(l, -1, 9)
+	if !slices.Equal(l, []int{0, 1, 2, 9, 3}) {
+		t.Error("insert should clamp the positions, got", l)
+	}
+}
+
+func TestListDeleteSlice(t *testing.T) {
+	for _, c := range []struct {
+		start, stop Any
+		step        int
+		want        []int
+	}{
+		{nil, nil, 2, []int{1, 3, 5}},
+		{1, nil, 2, []int{0, 2, 4}},
+		{nil, nil, -2, []int{0, 2, 4}},
+		{-2, 0, -1, []int{0, 5}},
+		{-100, 100, 3, []int{1, 2, 4, 5}},
+		{100, -100, -4, []int{0, 2, 3, 4}},
+	} {
+		l := []int{0, 1, 2, 3, 4, 5}
+		if got := ListDeleteSlice(l, c.start, c.stop, c.step); !slices.Equal(got, c.want) {
+			t.Errorf("del l[%v:%v:%v] should leave %v, got %v", c.start, c.stop, c.step, c.want, got)
+		}
+	}
+}
+
+func TestListSort(t *testing.T) {
+	l := List{3, 1.5, -2, 1}
+
+	ListSort(l, false)
+	if !Eq(l, List{-2, 1, 1.5, 3}) {
+		t.Error("sort() failed, got", l)
+	}
+
+	ListSort(l, true)
+	if !Eq(l, List{3, 1.5, 1, -2}) {
+		t.Error("sort(reverse=True) failed, got", l)
+	}
+
+	if v := ListFrom([]int{1, 2}); !Eq(v, List{1, 2}) {
+		t.Error("ListFrom([]int{1, 2}) should be [1, 2], got", v)
+	}
+
+	if v := ListFrom("ab"); !Eq(v, List{"a", "b"}) {
+		t.Error("ListFrom(\"ab\") should be ['a', 'b'], got", v)
+	}
+}
@@ -0,0 +1,660 @@
+package runtime
+
+import "fmt"
+import "math"
+import "math/big"
+import "math/cmplx"
+import "reflect"
+import "strings"
+
+//
+// The python numeric tower: bool < int < float < complex
+//
+const (
+	notNumber = iota
+	intNumber
+	floatNumber
+	complexNumber
+)
+
+//
+// Return the level of v in the numeric tower (notNumber if v is not a number)
+//
+func numberLevel(v Any) int {
+	switch v.(type) {
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, Int:
+		return intNumber
+
+	case float32, float64:
+		return floatNumber
+
+	case complex64, complex128:
+		return complexNumber
+	}
+
+	return notNumber
+}
+
+func toInt(v Any) int {
+	switch n := v.(type) {
+	case bool:
+		if n {
+			return 1
+		}
+		return 0
+
+	case int:
+		return n
+	case int8:
+		return int(n)
+	case int16:
+		return int(n)
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case uint:
+		return int(n)
+	case uint8:
+		return int(n)
+	case uint16:
+		return int(n)
+	case uint32:
+		return int(n)
+	case uint64:
+		return int(n)
+	case Int:
+		return n.Int()
+	}
+
+	panic(typeError("int", v))
+}
+
+//
+// Return true if any of the values is an Int (and the operation should use Int arithmetic)
+//
+func hasInt(values ...Any) bool {
+	for _, v := range values {
+		if _, ok := v.(Int); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toBigInt(v Any) Int {
+	if i, ok := v.(Int); ok {
+		return i
+	}
+
+	return NewInt(toInt(v))
+}
+
+func toFloat(v Any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case Int:
+		return n.Float64()
+	}
+
+	return float64(toInt(v))
+}
+
+func toComplex(v Any) complex128 {
+	switch n := v.(type) {
+	case complex128:
+		return n
+	case complex64:
+		return complex128(n)
+	}
+
+	return complex(toFloat(v), 0)
+}
+
+//
+// Python type name of v, for error messages
+//
+func typeName(v Any) string {
+	switch v.(type) {
+	case nil:
+		return "NoneType"
+	case bool:
+		return "bool"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, Int:
+		return "int"
+	case float32, float64:
+		return "float"
+	case complex64, complex128:
+		return "complex"
+	case string:
+		return "str"
+	case List:
+		return "list"
+	case Dict:
+		return "dict"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+func typeError(op string, values ...Any) string {
+	var types []string
+	for _, v := range values {
+		types = append(types, "'"+typeName(v)+"'")
+	}
+
+	return fmt.Sprintf("TypeError: unsupported operand type(s) for %v: %v", op, strings.Join(types, " and "))
+}
+
+func zeroDivision() string {
+	return "ZeroDivisionError: division by zero"
+}
+
+//
+// The common level of two numbers (notNumber if any of them is not a number)
+//
+func numberLevels(a, b Any) int {
+	la, lb := numberLevel(a), numberLevel(b)
+	if la == notNumber || lb == notNumber {
+		return notNumber
+	}
+
+	if la > lb {
+		return la
+	}
+
+	return lb
+}
+
+//
+// a + b (numbers, string and list concatenation)
+//
+func Add(a, b Any) Any {
+	switch numberLevels(a, b) {
+	case intNumber:
+		if hasInt(a, b) {
+			return toBigInt(a).Add(toBigInt(b))
+		}
+		return toInt(a) + toInt(b)
+	case floatNumber:
+		return toFloat(a) + toFloat(b)
+	case complexNumber:
+		return toComplex(a) + toComplex(b)
+	}
+
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return x + y
+		}
+
+	case List:
+		if y, ok := b.(List); ok {
+			return append(append(List{}, x...), y...)
+		}
+	}
+
+	panic(typeError("+", a, b))
+}
+
+//
+// a - b
+//
+func Sub(a, b Any) Any {
+	switch numberLevels(a, b) {
+	case intNumber:
+		if hasInt(a, b) {
+			return toBigInt(a).Sub(toBigInt(b))
+		}
+		return toInt(a) - toInt(b)
+	case floatNumber:
+		return toFloat(a) - toFloat(b)
+	case complexNumber:
+		return toComplex(a) - toComplex(b)
+	}
+
+	if x, ok := a.(Set); ok {
+		if y, ok := b.(Set); ok {
+			return x.Difference(y)
+		}
+	}
+
+	panic(typeError("-", a, b))
+}
+
+//
+// a * b (numbers, string and list repetition)
+//
+func Mul(a, b Any) Any {
+	switch numberLevels(a, b) {
+	case intNumber:
+		if hasInt(a, b) {
+			return toBigInt(a).Mul(toBigInt(b))
+		}
+		return toInt(a) * toInt(b)
+	case floatNumber:
+		return toFloat(a) * toFloat(b)
+	case complexNumber:
+		return toComplex(a) * toComplex(b)
+	}
+
+	// sequence * int or int * sequence
+	seq, n := a, b
+	if numberLevel(seq) == intNumber {
+		seq, n = b, a
+	}
+
+	if numberLevel(n) == intNumber {
+		times := toInt(n)
+		if times < 0 {
+			times = 0
+		}
+
+		switch x := seq.(type) {
+		case string:
+			return strings.Repeat(x, times)
+
+		case List:
+			l := make(List, 0, len(x)*times)
+			for i := 0; i < times; i++ {
+				l = append(l, x...)
+			}
+			return l
+		}
+	}
+
+	panic(typeError("*", a, b))
+}
+
+//
+// a / b (always a float, or complex)
+//
+func TrueDiv(a, b Any) Any {
+	switch numberLevels(a, b) {
+	case intNumber, floatNumber:
+		if hasInt(a, b) && numberLevels(a, b) == intNumber {
+			return toBigInt(a).TrueDiv(toBigInt(b))
+		}
+
+		d := toFloat(b)
+		if d == 0 {
+			panic(zeroDivision())
+		}
+		return toFloat(a) / d
+
+	case complexNumber:
+		d := toComplex(b)
+		if d == 0 {
+			panic(zeroDivision())
+		}
+		return toComplex(a) / d
+	}
+
+	panic(typeError("/", a, b))
+}
+
+//
+// Integer division rounding toward negative infinity (python //)
+//
+func FloorDivInt(a, b int) int {
+	if b == 0 {
+		panic(zeroDivision())
+	}
+
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+
+	return q
+}
+
+//
+// Integer modulo with the sign of the divisor (python %)
+//
+func ModInt(a, b int) int {
+	if b == 0 {
+		panic(zeroDivision())
+	}
+
+	m := a % b
+	if m != 0 && ((m < 0) != (b < 0)) {
+		m += b
+	}
+
+	return m
+}
+
+//
+// Float modulo with the sign of the divisor (python %)
+//
+func ModFloat(a, b float64) float64 {
+	if b == 0 {
+		panic(zeroDivision())
+	}
+
+	m := math.Mod(a, b)
+	if m != 0 && ((m < 0) != (b < 0)) {
+		m += b
+	}
+
+	return m
+}
+
+//
+// a // b
+//
+func FloorDiv(a, b Any) Any {
+	switch numberLevels(a, b) {
+	case intNumber:
+		if hasInt(a, b) {
+			return toBigInt(a).FloorDiv(toBigInt(b))
+		}
+		return FloorDivInt(toInt(a), toInt(b))
+
+	case floatNumber:
+		d := toFloat(b)
+		if d == 0 {
+			panic(zeroDivision())
+		}
+		return math.Floor(toFloat(a) / d)
+	}
+
+	panic(typeError("//", a, b))
+}
+
+//
+// a % b (numbers only, string formatting is translated to fmt.Sprintf)
+//
+func Mod(a, b Any) Any {
+	switch numberLevels(a, b) {
+	case intNumber:
+		if hasInt(a, b) {
+			return toBigInt(a).Mod(toBigInt(b))
+		}
+		return ModInt(toInt(a), toInt(b))
+
+	case floatNumber:
+		return ModFloat(toFloat(a), toFloat(b))
+	}
+
+	panic(typeError("%", a, b))
+}
+
+//
+// a ** b (an int if both are ints and b is not negative)
+//
+func Pow(a, b Any) Any {
+	switch numberLevels(a, b) {
+	case intNumber:
+		if hasInt(a, b) && toBigInt(b).Sign() >= 0 {
+			return toBigInt(a).Pow(toBigInt(b))
+		}
+		return IntPow(toInt(a), toInt(b))
+
+	case floatNumber:
+		return math.Pow(toFloat(a), toFloat(b))
+
+	case complexNumber:
+		return cmplx.Pow(toComplex(a), toComplex(b))
+	}
+
+	panic(typeError("** or pow()", a, b))
+}
+
+//
+// Integer power, by repeated squaring.
+// Python ints don't overflow, so instead of wrapping around this panics
+// (use BigPow for large values, or IntPow when the result may not fit an int)
+//
+func PowInt(base, exp int) int {
+	if exp < 0 {
+		panic("ValueError: negative exponent in integer power")
+	}
+
+	r, ok := powInt(base, exp)
+	if !ok {
+		panic("OverflowError: integer power too large")
+	}
+
+	return r
+}
+
+// base ** exp for exp >= 0, and false if the result doesn't fit an int
+func powInt(base, exp int) (int, bool) {
+	result := 1
+
+	for exp > 0 {
+		if exp&1 != 0 {
+			r, ok := mulInt(result, base)
+			if !ok {
+				return 0, false
+			}
+			result = r
+		}
+
+		exp >>= 1
+		if exp > 0 {
+			b, ok := mulInt(base, base)
+			if !ok {
+				return 0, false
+			}
+			base = b
+		}
+	}
+
+	return result, true
+}
+
+// a * b, and false if the result doesn't fit an int
+func mulInt(a, b int) (int, bool) {
+	r := a * b
+	if a != 0 && (r/a != b || a == -1 && b == math.MinInt || b == -1 && a == math.MinInt) {
+		return 0, false
+	}
+
+	return r, true
+}
+
+//
+// base ** exp for ints that are not constants: an int, an Int if the result doesn't fit an int,
+// or a float for a negative exponent
+//
+func IntPow(base, exp int) Any {
+	switch {
+	case exp < 0 && base == 0:
+		panic("ZeroDivisionError: 0.0 cannot be raised to a negative power")
+
+	case exp < 0:
+		return math.Pow(float64(base), float64(exp))
+	}
+
+	if r, ok := powInt(base, exp); ok {
+		return r
+	}
+
+	return fromBig(BigPow(base, exp))
+}
+
+//
+// Integer power that doesn't fit an int
+//
+func BigPow(base, exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(int64(base)), big.NewInt(int64(exp)), nil)
+}
+
+//
+// -a
+//
+func Neg(a Any) Any {
+	switch numberLevel(a) {
+	case intNumber:
+		if i, ok := a.(Int); ok {
+			return i.Neg()
+		}
+		return -toInt(a)
+	case floatNumber:
+		return -toFloat(a)
+	case complexNumber:
+		return -toComplex(a)
+	}
+
+	panic(fmt.Sprintf("TypeError: bad operand type for unary -: '%v'", typeName(a)))
+}
+
+//
+// a == b, with python rules: numbers of different types compare by value
+// and lists and dictionaries compare by content
+//
+func Eq(a, b Any) bool {
+	switch numberLevels(a, b) {
+	case intNumber:
+		if hasInt(a, b) {
+			return toBigInt(a).Cmp(toBigInt(b)) == 0
+		}
+		return toInt(a) == toInt(b)
+	case floatNumber:
+		return toFloat(a) == toFloat(b)
+	case complexNumber:
+		return toComplex(a) == toComplex(b)
+	}
+
+	switch x := a.(type) {
+	case List:
+		y, ok := b.(List)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+
+		for i := range x {
+			if !Eq(x[i], y[i]) {
+				return false
+			}
+		}
+
+		return true
+
+	case Set:
+		y, ok := b.(Set)
+		return ok && x.Equal(y)
+
+	case *OrderedDict:
+		y, ok := b.(*OrderedDict)
+		return ok && x.Equal(y)
+
+	case Dict:
+		y, ok := b.(Dict)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !Eq(v, w) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if reflect.TypeOf(a).Comparable() && reflect.TypeOf(b).Comparable() {
+		return a == b
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+//
+// a != b
+//
+func Ne(a, b Any) bool {
+	return !Eq(a, b)
+}
+
+//
+// Compare a and b, returning -1, 0 or 1 (numbers, strings and lists)
+//
+func compare(op string, a, b Any) int {
+	switch numberLevels(a, b) {
+	case intNumber:
+		if hasInt(a, b) {
+			return toBigInt(a).Cmp(toBigInt(b))
+		}
+
+		x, y := toInt(a), toInt(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+
+	case floatNumber:
+		x, y := toFloat(a), toFloat(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+
+	case List:
+		if y, ok := b.(List); ok {
+			for i := 0; i < len(x) && i < len(y); i++ {
+				if !Eq(x[i], y[i]) {
+					return compare(op, x[i], y[i])
+				}
+			}
+
+			return compare(op, len(x), len(y))
+		}
+	}
+
+	panic(fmt.Sprintf("TypeError: '%v' not supported between instances of '%v' and '%v'",
+		op, typeName(a), typeName(b)))
+}
+
+//
+// a < b
+//
+func Lt(a, b Any) bool {
+	return compare("<", a, b) < 0
+}
+
+//
+// a <= b
+//
+func Le(a, b Any) bool {
+	return compare("<=", a, b) <= 0
+}
+
+//
+// a > b
+//
+func Gt(a, b Any) bool {
+	return compare(">", a, b) > 0
+}
+
+//
+// a >= b
+//
+func Ge(a, b Any) bool {
+	return compare(">=", a, b) >= 0
+}
@@ -0,0 +1,167 @@
+package runtime
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	if v := Add(1, 2); v != 3 {
+		t.Error("1 + 2 should be 3, got", v)
+	}
+
+	if v := Add(1, 2.5); v != 3.5 {
+		t.Error("1 + 2.5 should be 3.5, got", v)
+	}
+
+	if v := Add(true, 1); v != 2 {
+		t.Error("True + 1 should be 2, got", v)
+	}
+
+	if v := Add("ab", "cd"); v != "abcd" {
+		t.Error("'ab' + 'cd' should be 'abcd', got", v)
+	}
+
+	if v := Add(List{1}, List{2, 3}); !Eq(v, List{1, 2, 3}) {
+		t.Error("[1] + [2, 3] should be [1, 2, 3], got", v)
+	}
+}
+
+func TestMul(t *testing.T) {
+	if v := Mul(3, 4.0); v != 12.0 {
+		t.Error("3 * 4.0 should be 12.0, got", v)
+	}
+
+	if v := Mul("ab", 3); v != "ababab" {
+		t.Error("'ab' * 3 should be 'ababab', got", v)
+	}
+
+	if v := Mul(2, List{1}); !Eq(v, List{1, 1}) {
+		t.Error("2 * [1] should be [1, 1], got", v)
+	}
+}
+
+func TestDivision(t *testing.T) {
+	if v := TrueDiv(7, 2); v != 3.5 {
+		t.Error("7 / 2 should be 3.5, got", v)
+	}
+
+	if v := FloorDiv(-7, 2); v != -4 {
+		t.Error("-7 // 2 should be -4, got", v)
+	}
+
+	if v := FloorDiv(7.0, -2); v != -4.0 {
+		t.Error("7.0 // -2 should be -4.0, got", v)
+	}
+
+	if v := Mod(-7, 2); v != 1 {
+		t.Error("-7 % 2 should be 1, got", v)
+	}
+
+	if v := Mod(7, -2); v != -1 {
+		t.Error("7 % -2 should be -1, got", v)
+	}
+
+	if v := Mod(-7.5, 2); v != 0.5 {
+		t.Error("-7.5 % 2 should be 0.5, got", v)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("1 // 0 should raise ZeroDivisionError")
+		}
+	}()
+
+	FloorDiv(1, 0)
+}
+
+func TestPow(t *testing.T) {
+	if v := Pow(2, 10); v != 1024 {
+		t.Error("2 ** 10 should be 1024, got", v)
+	}
+
+	if v := Pow(2, -1); v != 0.5 {
+		t.Error("2 ** -1 should be 0.5, got", v)
+	}
+
+	if v := Pow(4.0, 0.5); v != 2.0 {
+		t.Error("4.0 ** 0.5 should be 2.0, got", v)
+	}
+
+	if v := PowInt(-3, 3); v != -27 {
+		t.Error("-3 ** 3 should be -27, got", v)
+	}
+
+	if v := BigPow(2, 100).String(); v != "1267650600228229401496703205376" {
+		t.Error("2 ** 100 should be 1267650600228229401496703205376, got", v)
+	}
+
+	if v := IntPow(3, 4); v != 81 {
+		t.Error("3 ** 4 should be 81, got", v)
+	}
+
+	if v := IntPow(10, 30); Str(v) != "1000000000000000000000000000000" {
+		t.Error("10 ** 30 should be an Int, got", v)
+	}
+
+	if v := IntPow(-2, 63); v != -1<<63 {
+		t.Error("-2 ** 63 should be the smallest int, got", v)
+	}
+
+	if v := IntPow(2, 63); Str(v) != "9223372036854775808" {
+		t.Error("2 ** 63 should be an Int, got", v)
+	}
+
+	if v := IntPow(2, -2); v != 0.25 {
+		t.Error("2 ** -2 should be 0.25, got", v)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("10 ** 30 should overflow")
+		}
+	}()
+
+	PowInt(10, 30)
+}
+
+func TestNeg(t *testing.T) {
+	if v := Neg(3); v != -3 {
+		t.Error("-3 should be -3, got", v)
+	}
+
+	if v := Neg(1.5); v != -1.5 {
+		t.Error("-1.5 should be -1.5, got", v)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	if !Eq(1, 1.0) {
+		t.Error("1 == 1.0 should be true")
+	}
+
+	if Eq("1", 1) {
+		t.Error("'1' == 1 should be false")
+	}
+
+	if !Eq(Dict{"a": 1}, Dict{"a": 1.0}) {
+		t.Error("{'a': 1} == {'a': 1.0} should be true")
+	}
+
+	if !Lt(1, 1.5) || Lt(2, 1) {
+		t.Error("1 < 1.5 should be true and 2 < 1 false")
+	}
+
+	if !Le("abc", "abd") || !Ge("b", "b") || !Gt("b", "a") {
+		t.Error("string comparison failed")
+	}
+
+	if !Lt(List{1, 2}, List{1, 3}) || !Lt(List{1}, List{1, 0}) {
+		t.Error("list comparison failed")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("1 < 'a' should raise TypeError")
+		}
+	}()
+
+	Lt(1, "a")
+}
@@ -0,0 +1,146 @@
+package runtime
+
+import "fmt"
+import "iter"
+import "math"
+
+//
+// Range is a python range: the ints from Start to Stop (excluded), by Step
+//
+type Range struct {
+	Start, Stop, Step int
+}
+
+//
+// range(stop), range(start, stop) or range(start, stop, step)
+//
+func NewRange(args ...int) Range {
+	switch len(args) {
+	case 1:
+		return Range{Start: 0, Stop: args[0], Step: 1}
+
+	case 2:
+		return Range{Start: args[0], Stop: args[1], Step: 1}
+
+	case 3:
+		if args[2] == 0 {
+			panic("ValueError: range() arg 3 must not be zero")
+		}
+		return Range{Start: args[0], Stop: args[1], Step: args[2]}
+	}
+
+	panic(fmt.Sprintf("TypeError: range expected at most 3 arguments, got %v", len(args)))
+}
+
+//
+// len(r)
+//
+func (r Range) Len() int {
+	switch {
+	case r.Step > 0 && r.Start < r.Stop:
+		return (r.Stop - r.Start + r.Step - 1) / r.Step
+
+	case r.Step < 0 && r.Start > r.Stop:
+		return (r.Start - r.Stop - r.Step - 1) / -r.Step
+	}
+
+	return 0
+}
+
+//
+// r[i] (negative indexes count from the end)
+//
+func (r Range) Item(i int) int {
+	n := r.Len()
+	if i < 0 {
+		i += n
+	}
+
+	if i < 0 || i >= n {
+		panic("IndexError: range object index out of range")
+	}
+
+	return r.Start + i*r.Step
+}
+
+//
+// r[start:stop], as a Range.
+// As for lists, negative indexes count from the end and out of range indexes are clipped
+//
+func (r Range) Slice(start, stop int) Range {
+	n := r.Len()
+	clip := func(i int) int {
+		if i < 0 {
+			i += n
+		}
+		return min(max(i, 0), n)
+	}
+
+	start, stop = clip(start), clip(stop)
+	return Range{Start: r.Start + start*r.Step, Stop: r.Start + stop*r.Step, Step: r.Step}
+}
+
+//
+// v in r (v can be any number equal to an element of r)
+//
+func (r Range) Contains(v Any) bool {
+	switch numberLevel(v) {
+	case intNumber:
+		n := toBigInt(v)
+		if !n.IsSmall() {
+			return false
+		}
+		return r.contains(n.Int())
+
+	case floatNumber:
+		f := toFloat(v)
+		return f == math.Trunc(f) && math.Abs(f) < 1<<62 && r.contains(int(f))
+	}
+
+	return false
+}
+
+func (r Range) contains(i int) bool {
+	if r.Step > 0 && (i < r.Start || i >= r.Stop) || r.Step < 0 && (i > r.Start || i <= r.Stop) {
+		return false
+	}
+
+	return (i-r.Start)%r.Step == 0
+}
+
+//
+// Iterate over the elements of r
+//
+func (r Range) All() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, n := r.Start, r.Len(); n > 0; i, n = i+r.Step, n-1 {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+//
+// Iterate over the elements of r as Ints (for -bigint mode)
+//
+func (r Range) Ints() iter.Seq[Int] {
+	return func(yield func(Int) bool) {
+		for i := range r.All() {
+			if !yield(NewInt(i)) {
+				return
+			}
+		}
+	}
+}
+
+//
+// The python representation: range(0, 5) or range(0, 10, 2)
+//
+func (r Range) String() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("range(%v, %v)", r.Start, r.Stop)
+	}
+
+	return fmt.Sprintf("range(%v, %v, %v)", r.Start, r.Stop, r.Step)
+}
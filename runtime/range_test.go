@@ -0,0 +1,63 @@
+package runtime
+
+import "slices"
+import "testing"
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		r    Range
+		want []int
+	}{
+		{NewRange(5), []int{0, 1, 2, 3, 4}},
+		{NewRange(2, 5), []int{2, 3, 4}},
+		{NewRange(0, 10, 3), []int{0, 3, 6, 9}},
+		{NewRange(5, 0, -2), []int{5, 3, 1}},
+		{NewRange(5, 0), []int{}},
+		{NewRange(-3), []int{}},
+	}
+
+	for _, test := range tests {
+		if l := slices.Collect(test.r.All()); !slices.Equal(l, test.want) {
+			t.Errorf("%v should be %v, got %v", test.r, test.want, l)
+		}
+
+		if n := test.r.Len(); n != len(test.want) {
+			t.Errorf("len(%v) should be %v, got %v", test.r, len(test.want), n)
+		}
+
+		for _, v := range test.want {
+			if !test.r.Contains(v) {
+				t.Errorf("%v should contain %v", test.r, v)
+			}
+		}
+	}
+
+	r := NewRange(10, 0, -3) // 10, 7, 4, 1
+	if r.Item(1) != 7 || r.Item(-1) != 1 {
+		t.Error("range(10, 0, -3) indexing failed")
+	}
+
+	if r.Contains(8) || r.Contains(0) || !r.Contains(4.0) || r.Contains(4.5) || r.Contains("4") {
+		t.Error("range(10, 0, -3) containment failed")
+	}
+
+	if s := r.Slice(1, -1); s != NewRange(7, 1, -3) {
+		t.Error("range(10, 0, -3)[1:-1] should be range(7, 1, -3), got", s)
+	}
+
+	if v := ListFrom(r.Slice(-2, 100)); !Eq(v, List{4, 1}) {
+		t.Error("list(range(10, 0, -3)[-2:]) should be [4, 1], got", v)
+	}
+
+	if s := Str(NewRange(3)); s != "range(0, 3)" {
+		t.Error("str(range(3)) should be range(0, 3), got", s)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("range(10, 0, -3)[4] should raise IndexError")
+		}
+	}()
+
+	r.Item(4)
+}
@@ -1,6 +1,7 @@
 package runtime
 
 import "fmt"
+import "reflect"
 import "regexp"
 import "strings"
 import "unicode"
@@ -19,6 +20,108 @@ func Assert(cond bool, message string) {
 	}
 }
 
+//
+// Truth value of v, following python rules: None, False, zero,
+// empty strings and empty collections are false.
+// Objects can implement Bool() bool (__bool__) or Len() int (__len__)
+//
+func Truthy(v Any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+
+	case bool:
+		return t
+
+	case int:
+		return t != 0
+
+	case float64:
+		return t != 0
+
+	case string:
+		return len(t) > 0
+
+	case List: // or Tuple
+		return len(t) > 0
+
+	case Dict:
+		return len(t) > 0
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return false
+	}
+
+	switch t := v.(type) {
+	case interface{ Bool() bool }:
+		return t.Bool()
+
+	case interface{ Len() int }:
+		return t.Len() > 0
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() != 0
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint() != 0
+
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() != 0
+
+	case reflect.Complex64, reflect.Complex128:
+		return rv.Complex() != 0
+
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		return rv.Len() > 0
+
+	case reflect.Ptr, reflect.Interface, reflect.Func:
+		return !rv.IsNil()
+	}
+
+	return true
+}
+
+//
+// The conditional expression `a if cond else b`, for values that can be evaluated
+// before the condition (both a and b are always evaluated)
+//
+func IfElse[T any](cond bool, a, b T) T {
+	if cond {
+		return a
+	}
+
+	return b
+}
+
+//
+// The i-th optional argument of a lambda (passed as variadic arguments), or def if it wasn't passed.
+// An int is accepted for a float argument, as python does, and None is the zero value of T
+//
+func OptionalArg[T any](args []Any, i int, def T) T {
+	if i >= len(args) {
+		return def
+	}
+
+	if _, ok := any(def).(float64); ok && numberLevel(args[i]) == intNumber {
+		return any(toFloat(args[i])).(T)
+	}
+
+	if v, ok := args[i].(T); ok {
+		return v
+	}
+
+	if args[i] == nil {
+		var zero T
+		return zero
+	}
+
+	panic(fmt.Sprintf("TypeError: unexpected argument %v", Repr(args[i])))
+}
+
 //
 // Check that bag contains value
 //
@@ -37,6 +140,15 @@ func Contains(bag, value interface{}) bool {
 			}
 		}
 
+	case Set:
+		return c.Contains(value)
+
+	case *OrderedDict:
+		return c.Contains(value)
+
+	case Range:
+		return c.Contains(value)
+
 	case string:
 		if s, ok := value.(string); ok {
 			return strings.Contains(c, s)
@@ -173,3 +285,18 @@ func Reverse(l List) {
 		right -= 1
 	}
 }
+
+//
+// Merge dictionaries (as in {**a, **b}), the last one wins
+//
+func MergeDicts(dicts ...Dict) Dict {
+	merged := Dict{}
+
+	for _, d := range dicts {
+		for k, v := range d {
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
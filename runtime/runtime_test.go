@@ -6,6 +6,36 @@ func TestAssert(t *testing.T) {
 	Assert(true, "this should be true")
 }
 
+func TestIfElse(t *testing.T) {
+	if v := IfElse(true, 1, 2); v != 1 {
+		t.Error("1 if True else 2 should be 1, got", v)
+	}
+
+	if v := IfElse[Any](false, 1, "two"); v != "two" {
+		t.Error("1 if False else 'two' should be 'two', got", v)
+	}
+}
+
+func TestOptionalArg(t *testing.T) {
+	args := []Any{5, 2}
+
+	if v := OptionalArg(args, 0, 10); v != 5 {
+		t.Error("the passed argument should be 5, got", v)
+	}
+
+	if v := OptionalArg(args, 1, 0.5); v != 2.0 {
+		t.Error("an int argument should be accepted as a float, got", v)
+	}
+
+	if v := OptionalArg(args, 2, "default"); v != "default" {
+		t.Error("a missing argument should be the default, got", v)
+	}
+
+	if v := OptionalArg([]Any{nil}, 0, Any(5)); v != nil {
+		t.Error("None should be passed as nil, got", v)
+	}
+}
+
 func TestContainsString(t *testing.T) {
 	bag := "the quick brown fox"
 
@@ -163,3 +193,50 @@ func TestSplits(t *testing.T) {
 		t.Error("incorrect split")
 	}
 }
+
+func TestMergeDicts(t *testing.T) {
+	d := MergeDicts(Dict{"one": 1, "two": 2}, Dict{"two": 22, "three": 3})
+	if len(d) != 3 {
+		t.Error("incorrect merge", d)
+	}
+
+	if d["two"] != 22 {
+		t.Error("last dictionary should win", d)
+	}
+}
+
+type sized struct {
+	n int
+}
+
+func (s *sized) Len() int {
+	return s.n
+}
+
+func TestTruthy(t *testing.T) {
+	var nilptr *sized
+
+	for _, v := range []Any{true, 1, -1, 0.5, "a", List{0}, Dict{"a": nil}, []int{0}, &sized{n: 1}, int8(3), complex(0, 1)} {
+		if !Truthy(v) {
+			t.Errorf("%#v should be true", v)
+		}
+	}
+
+	for _, v := range []Any{nil, false, 0, 0.0, "", List{}, Dict{}, []string{}, map[int]int{}, &sized{}, nilptr, uint(0)} {
+		if Truthy(v) {
+			t.Errorf("%#v should be false", v)
+		}
+	}
+}
+
+func TestContainsSet(t *testing.T) {
+	bag := NewSet("one", 2, 3.0)
+
+	if !Contains(bag, 2) {
+		t.Error(bag, "should contain 2")
+	}
+
+	if Contains(bag, "four") {
+		t.Error(bag, "should not contain four")
+	}
+}
@@ -0,0 +1,321 @@
+package runtime
+
+import "fmt"
+import "sort"
+import "strings"
+
+//
+// SetOf is a python set (or frozenset) of values of type T.
+// The map values are the elements, the keys the values used to hash them:
+// for a Set the hash keys of OrderedDict, so that equal numbers and tuples are the same element
+//
+type SetOf[T comparable] map[T]T
+
+//
+// Set is a python set of values of any (hashable) type
+//
+type Set = SetOf[Any]
+
+//
+// Return a new Set containing items
+//
+func NewSet(items ...Any) Set {
+	return NewSetOf(items...)
+}
+
+//
+// Return a new SetOf[T] containing items
+//
+func NewSetOf[T comparable](items ...T) SetOf[T] {
+	s := make(SetOf[T], len(items))
+	for _, v := range items {
+		s.Add(v)
+	}
+
+	return s
+}
+
+//
+// Return the key used to hash v: v itself for a concrete type T, its hash key for Any
+//
+func setKey[T comparable](v T) T {
+	var zero T
+	if any(zero) != nil {
+		return v
+	}
+
+	k, _ := hashKey(v).(T)
+	return k
+}
+
+//
+// Return a new Set with the elements of iterable (a set, list, dict, string or any other iterable)
+//
+func SetFrom(iterable Any) Set {
+	s := Set{}
+
+	switch c := iterable.(type) {
+	case nil:
+		// set()
+
+	case Set:
+		s.Update(c)
+
+	case List:
+		for _, v := range c {
+			s.Add(v)
+		}
+
+	case Dict:
+		for k := range c {
+			s.Add(k)
+		}
+
+	case *OrderedDict:
+		for k := range c.All() {
+			s.Add(k)
+		}
+
+	default: // strings and anything else Iterate accepts
+		for v := range Iterate(iterable) {
+			s.Add(v)
+		}
+	}
+
+	return s
+}
+
+//
+// Return the number of elements in the set
+//
+func (s SetOf[T]) Len() int {
+	return len(s)
+}
+
+//
+// Return true if the set contains v
+//
+func (s SetOf[T]) Contains(v T) bool {
+	_, ok := s[setKey(v)]
+	return ok
+}
+
+//
+// Add v to the set (if an equal element is present, it is kept)
+//
+func (s SetOf[T]) Add(v T) {
+	k := setKey(v)
+	if _, ok := s[k]; !ok {
+		s[k] = v
+	}
+}
+
+//
+// Remove v from the set, if present
+//
+func (s SetOf[T]) Discard(v T) {
+	delete(s, setKey(v))
+}
+
+//
+// Remove v from the set (panics with KeyError if not present)
+//
+func (s SetOf[T]) Remove(v T) {
+	k := setKey(v)
+	if _, ok := s[k]; !ok {
+		panic(fmt.Sprintf("KeyError: %v", Repr(v)))
+	}
+
+	delete(s, k)
+}
+
+//
+// Remove and return an arbitrary element (panics with KeyError if the set is empty)
+//
+func (s SetOf[T]) Pop() T {
+	for k, v := range s {
+		delete(s, k)
+		return v
+	}
+
+	panic("KeyError: 'pop from an empty set'")
+}
+
+//
+// Remove all elements
+//
+func (s SetOf[T]) Clear() {
+	clear(s)
+}
+
+//
+// Return a shallow copy of the set
+//
+func (s SetOf[T]) Copy() SetOf[T] {
+	c := make(SetOf[T], len(s))
+	for k, v := range s {
+		c[k] = v
+	}
+
+	return c
+}
+
+//
+// Add the elements of all the other sets (s |= other)
+//
+func (s SetOf[T]) Update(others ...SetOf[T]) {
+	for _, o := range others {
+		for k, v := range o {
+			if _, ok := s[k]; !ok {
+				s[k] = v
+			}
+		}
+	}
+}
+
+//
+// Return the elements that are in s or in any of the other sets (s | other)
+//
+func (s SetOf[T]) Union(others ...SetOf[T]) SetOf[T] {
+	u := s.Copy()
+	u.Update(others...)
+	return u
+}
+
+//
+// Return the elements that are in s and in all the other sets (s & other)
+//
+func (s SetOf[T]) Intersection(others ...SetOf[T]) SetOf[T] {
+	r := SetOf[T]{}
+
+	for k, v := range s {
+		in := true
+		for _, o := range others {
+			if _, ok := o[k]; !ok {
+				in = false
+				break
+			}
+		}
+
+		if in {
+			r[k] = v
+		}
+	}
+
+	return r
+}
+
+//
+// Return the elements that are in s but not in any of the other sets (s - other)
+//
+func (s SetOf[T]) Difference(others ...SetOf[T]) SetOf[T] {
+	r := s.Copy()
+	for _, o := range others {
+		for k := range o {
+			delete(r, k)
+		}
+	}
+
+	return r
+}
+
+//
+// Return the elements that are either in s or in other, but not in both (s ^ other)
+//
+func (s SetOf[T]) SymmetricDifference(other SetOf[T]) SetOf[T] {
+	r := s.Difference(other)
+	for k, v := range other {
+		if _, ok := s[k]; !ok {
+			r[k] = v
+		}
+	}
+
+	return r
+}
+
+//
+// Return true if all the elements of s are in other (s <= other)
+//
+func (s SetOf[T]) IsSubset(other SetOf[T]) bool {
+	if len(s) > len(other) {
+		return false
+	}
+
+	for k := range s {
+		if _, ok := other[k]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+//
+// Return true if all the elements of other are in s (s >= other)
+//
+func (s SetOf[T]) IsSuperset(other SetOf[T]) bool {
+	return other.IsSubset(s)
+}
+
+//
+// Return true if s is a subset of other, but not equal (s < other)
+//
+func (s SetOf[T]) IsProperSubset(other SetOf[T]) bool {
+	return len(s) < len(other) && s.IsSubset(other)
+}
+
+//
+// Return true if s is a superset of other, but not equal (s > other)
+//
+func (s SetOf[T]) IsProperSuperset(other SetOf[T]) bool {
+	return other.IsProperSubset(s)
+}
+
+//
+// Return true if s and other have no elements in common
+//
+func (s SetOf[T]) IsDisjoint(other SetOf[T]) bool {
+	for k := range s {
+		if _, ok := other[k]; ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+//
+// Return true if s and other contain the same elements (s == other)
+//
+func (s SetOf[T]) Equal(other SetOf[T]) bool {
+	return len(s) == len(other) && s.IsSubset(other)
+}
+
+//
+// Return the elements of the set, as a slice (in arbitrary order)
+//
+func (s SetOf[T]) Items() []T {
+	items := make([]T, 0, len(s))
+	for _, v := range s {
+		items = append(items, v)
+	}
+
+	return items
+}
+
+//
+// Format the set as python does (the elements are sorted, to get a stable output)
+//
+func (s SetOf[T]) String() string {
+	if len(s) == 0 {
+		return "set()"
+	}
+
+	items := make([]string, 0, len(s))
+	for _, v := range s {
+		items = append(items, Repr(v))
+	}
+
+	sort.Strings(items)
+	return "{" + strings.Join(items, ", ") + "}"
+}
@@ -0,0 +1,83 @@
+package runtime
+
+import "slices"
+import "testing"
+
+func TestSetOperations(t *testing.T) {
+	a := NewSetOf(1, 2, 3)
+	b := NewSetOf(3, 4)
+
+	if u := a.Union(b); !u.Equal(NewSetOf(1, 2, 3, 4)) {
+		t.Error("a | b should be {1, 2, 3, 4}, got", u)
+	}
+
+	if i := a.Intersection(b); !i.Equal(NewSetOf(3)) {
+		t.Error("a & b should be {3}, got", i)
+	}
+
+	if d := a.Difference(b); !d.Equal(NewSetOf(1, 2)) {
+		t.Error("a - b should be {1, 2}, got", d)
+	}
+
+	if x := a.SymmetricDifference(b); !x.Equal(NewSetOf(1, 2, 4)) {
+		t.Error("a ^ b should be {1, 2, 4}, got", x)
+	}
+
+	if !NewSetOf(1, 2).IsProperSubset(a) || a.IsProperSubset(a) || !a.IsSubset(a) || a.IsDisjoint(b) {
+		t.Error("subset comparison failed")
+	}
+}
+
+func TestSetMethods(t *testing.T) {
+	s := NewSet()
+	s.Add("a")
+	s.Add(1)
+	s.Discard("b")
+	s.Remove(1)
+
+	if s.String() != `{'a'}` {
+		t.Error(`s should be {'a'}, got`, s)
+	}
+
+	if v := s.Pop(); v != "a" || s.Len() != 0 {
+		t.Error("pop should return the last element, got", v)
+	}
+
+	if v := SetFrom("abca"); v.Len() != 3 || !v.Contains("c") {
+		t.Error("set('abca') should be {'a', 'b', 'c'}, got", v)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("remove of a missing element should raise KeyError")
+		}
+	}()
+
+	s.Remove("missing")
+}
+
+func TestSetHashing(t *testing.T) {
+	s := NewSet(Tuple{1, 2}, Tuple{1, 2}, Tuple{1, "2"})
+	if s.Len() != 2 || !s.Contains(Tuple{1, 2}) || s.Contains(Tuple{2, 1}) {
+		t.Error("{(1, 2), (1, 2), (1, '2')} should have 2 tuples, got", s)
+	}
+
+	// equal numbers are the same element, the first one added is kept
+	n := NewSet(1, 1.0, true)
+	if n.Len() != 1 || !n.Contains(1.0) || n.Items()[0] != 1 {
+		t.Error("{1, 1.0, True} should be {1}, got", n)
+	}
+
+	n.Discard(true)
+	if n.Len() != 0 {
+		t.Error("discard(True) should remove 1, got", n)
+	}
+
+	if u := NewSet(Tuple{"a", 1}).Union(SetFrom(List{Tuple{"a", 1.0}})); u.Len() != 1 {
+		t.Error("{('a', 1)} | {('a', 1.0)} should have one element, got", u)
+	}
+
+	if l := slices.Collect(Iterate(NewSet(Tuple{"x"}))); !Eq(l, List{Tuple{"x"}}) {
+		t.Error("iterating over a set should produce its elements, got", l)
+	}
+}
@@ -5,3 +5,36 @@ a,b,c = 1,2,3
 a = [1, 2, 3, 4, 5]
 b = (1, 2, 3, 4, 5)
 c = ((1, 2), (3, 4), (5, 6))
+x = 1, 2, 3
+
+
+def divmod2(a, b):
+    if b == 0:
+        return 0, 0
+    return a // b, a % b
+
+
+def unpack(seq, pairs):
+    a, b = 1, 2
+    a, b = b, a
+    print(a, b)
+
+    (x, y), z = seq
+    first, *rest = seq
+    *init, last = seq
+    head, *middle, tail = seq
+    print(x, y, z, first, rest, init, last, head, middle, tail)
+
+    q, r = divmod2(7, 2)
+    (q, r), n = divmod2(9, 4), 3
+    print(q, r, n)
+
+    i = j = len(seq)
+    m = n = [0]
+    print(i, j, m, n)
+
+    for (k, v), w in pairs:
+        print(k, v, w)
+
+    for k, *vs in pairs:
+        print(k, vs)
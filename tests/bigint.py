@@ -0,0 +1,18 @@
+def factorial(n: int) -> int:
+    result = 1
+    for i in range(2, n + 1):
+        result *= i
+    return result
+
+def digits(n: int) -> int:
+    count = 0
+    while n:
+        n //= 10
+        count += 1
+    return count
+
+def mean(values: list) -> float:
+    return sum(values) / len(values)
+
+huge = 123456789012345678901234567890
+print(factorial(30), digits(huge), -huge % 7, huge > 2 ** 64)
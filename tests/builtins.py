@@ -0,0 +1,26 @@
+def stats(values: list[int], weights: list[float], names: list[str]):
+    total = sum(values)
+    mean = sum(weights, 0.0) / len(weights)
+    print(min(values), max(weights), min(total, 100), max(names, key=len, default=""))
+    print(sorted(names), sorted(names, key=len, reverse=True), abs(total), round(mean, 2))
+    print(any(values), all(names), divmod(total, 7), hex(total), chr(65 + total % 26), ord("a"))
+    for i, name in enumerate(names, start=1):
+        print(i, name)
+    for v, w in zip(values, weights):
+        print(v * w)
+    for v in reversed(values):
+        print(v)
+    return map(len, names), filter(None, values)
+
+def shadowed(xs: list[int], s: int, a: int, l: list[int]):
+    # the names of the variables used by the translation of the builtins
+    m = 2
+    print(sum(xs, s), sorted(xs, key=lambda x: x * a), min(xs, key=lambda v: v % m, default=s))
+    print(list(map(lambda r: r + a, l)), list(filter(lambda v: v > m, xs)), max(l, key=lambda k: k - a))
+
+def first(items):
+    it = iter(items)
+    return next(it, None), len(items), sorted(items), sum(items), hash(items[0])
+
+print(stats([3, 1, 2], [0.5, 1.5], ["ab", "c"]), first([1, 2]))
+shadowed([3, 1, 2], 10, -1, [5, 6])
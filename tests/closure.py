@@ -0,0 +1,32 @@
+counter = 0
+
+def incr():
+    global counter
+    counter += 1
+
+def reset():
+    global total
+    total = 0
+
+def make_acc():
+    acc = 0
+
+    def add(n):
+        nonlocal acc
+        acc += n
+        return acc
+
+    return add
+
+def branches(c):
+    if c:
+        x = 1
+    else:
+        x = 2
+    for i in range(3):
+        last = i
+    return x + last
+
+def shadow():
+    counter = 10
+    return counter
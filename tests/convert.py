@@ -0,0 +1,12 @@
+def parse(line: str, scale: float):
+    fields = line.split(",")
+    count = int(fields[0])
+    mask = int(fields[1], 16)
+    value = float(fields[2]) * scale
+    print(str(count), str(value), repr(line), bool(mask), int(value))
+    return str(count + mask) + " " + str(float(count))
+
+def describe(x):
+    return str(x) + " " + repr(x) + " " + str(int(x)) + " " + str(bool(x))
+
+print(parse("10,ff,2.5", 2.0), describe(3.5))
@@ -3,3 +3,11 @@
 print({x.upper():len(x) for x in ["one", "two", "three", "four", "five", "six"]})
 
 print({x.upper():len(x) for x in ["one", "two", "three", "four", "five", "six"] if len(x) <= 4})
+
+def index(words: list[str]) -> dict[str, int]:
+    return {w: i for i, w in enumerate(words)}
+
+def inverse(d: dict[str, int]) -> dict[int, str]:
+    return {v: k for k, v in d.items() if v > 0}
+
+print(index(["a", "b"]), inverse({"a": 0, "b": 1}))
@@ -0,0 +1,30 @@
+def greet(name, greeting="hello", punct="!"):
+    print(greeting, name, punct)
+
+def scale(x, *, factor=2):
+    return x * factor
+
+greet("world")
+greet("world", "hi")
+greet("world", punct="?")
+print(scale(3, factor=10))
+
+class Counter:
+    def incr(self, n=1):
+        self.count += n
+
+def append_to(x, items=[], unused=None):
+    # the default list is created once, and shared by the calls (as in python)
+    items.append(x)
+    return items
+
+print(append_to(1), append_to(2))
+
+def outer():
+    def greet(who, punct="."):
+        # hides the top-level greet
+        print("nested", who, punct)
+
+    greet("you")
+
+outer()
@@ -0,0 +1,30 @@
+def count_words(words):
+    counts = {}
+    for w in words:
+        counts[w] = counts.get(w, 0) + 1
+    return counts
+
+def invert(d: dict):
+    inv = {v: k for k, v in d.items()}
+    for k in d:
+        print(k)
+    for v in d.values():
+        print(v)
+    if 1 in inv and inv:
+        del inv[1]
+    return inv
+
+grid = {(0, 0): "origin", 1: "one", "name": "grid"}
+grid[(1, 1)] = "diagonal"
+grid["count"] = 0
+grid["count"] += 1
+merged = grid | {"extra": True}
+merged |= dict(more=1)
+other = dict(grid, last=None)
+print(len(grid), grid.pop("name"), grid.setdefault("x", []), grid.popitem(), merged == other)
+print(list(grid.keys()), grid.copy())
+grid.update(a=1)
+grid.clear()
+pairs = dict(zip(["a", "b"], [1, 2]))
+squares = dict((n, n * n) for n in range(3))
+print(pairs, squares)
@@ -0,0 +1,24 @@
+def averages(values: list, n: int, scale: float):
+    total = 0
+    for v in range(n):
+        total += v
+    mean = total / n
+    half = n // 2
+    rest = -n % 3
+    fscaled = scale // 2
+    squared = n ** 2
+    inverse = n ** -1
+    big = 2 ** 100
+    mixed = (total + n) * scale
+    total //= 2
+    scale **= 2
+    return mean, half, rest, fscaled, squared, inverse, big, mixed, total, scale
+
+
+def halves(n: int):
+    x = n
+    x /= 2 # x becomes a float
+    p = 2
+    p **= n # p stays an int
+    half = 1 / 2
+    return x, p, n ** 70, 1 ** 10 ** 12, half, 3 / n
@@ -0,0 +1,22 @@
+# test operators on values of unknown type
+
+def combine(a, b):
+    total = a + b
+    total -= 1
+    if a < b:
+        return -total
+    return total * 2
+
+def concat(a, b):
+    return a + b
+
+def ratio(a, b):
+    return a / b, a // b, a % b, a ** b
+
+def same(a, b):
+    return a == b or a is None
+
+print(combine(1, 2), combine(2.5, 1))
+print(concat("x", "y"), concat([1], [2]))
+print(ratio(7, 2))
+print(same([1, 2], [1, 2]))
@@ -9,3 +9,36 @@ for v in gen1:
 for v in gen2:
     print(v)
 
+
+def evens(xs: list[int]) -> list[int]:
+    return list(x for x in xs if x % 2 == 0)
+
+def big(xs: list[int]) -> bool:
+    return any(x > 5 for x in xs)
+
+def positive(xs: list[int]) -> bool:
+    return all(x > 0 for x in xs)
+
+def total(words: list[str]) -> int:
+    return sum(len(s) for s in words)
+
+def longest(words: list[str]) -> int:
+    return max(len(w) for w in words)
+
+squares = (i * i for i in range(5))
+
+for v in squares:
+    if v > 4:
+        break
+    print(v)
+
+print(evens([1, 2, 3, 4]), big([1, 7]), positive([1, 2]), total(["a", "bc"]), longest(["a", "bc"]))
+print(", ".join(w.upper() for w in ["a", "b"]))
+
+# generators are single-use: this continues after the element that stopped the first loop
+for v in squares:
+    print(v)
+
+letters = (c for c in "abc")
+print(next(letters), next(letters), list(letters), list(letters))
+print(next(x for x in [3, 4] if x > 3))
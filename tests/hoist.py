@@ -0,0 +1,33 @@
+def classify(n):
+    if n < 0:
+        label = "negative"
+    elif n == 0:
+        label = "zero"
+    else:
+        label = "positive"
+    return label
+
+def last_even(items):
+    for x in items:
+        if x % 2 == 0:
+            found = x
+    return found
+
+def last_item(items: list[str], n: int):
+    for x in items:
+        pass
+    for i in range(n):
+        pass
+    print(x, i)
+
+def first_error(values):
+    try:
+        result = 1 / values[0]
+    except ZeroDivisionError as e:
+        print("error:", e)
+        result = 0
+    return result
+
+def local_import():
+    import math
+    return math.pi
@@ -0,0 +1,20 @@
+def sign(n):
+    return -1 if n < 0 else 1 if n > 0 else 0
+
+
+def first(items):
+    return items[0] if items else None
+
+
+def conditional(n, items, name):
+    parity = "even" if n % 2 == 0 else "odd"
+    size = len(items) if items else 0
+    print(parity, size, "hello " + name if name else "hello")
+
+    label = None
+    label = "big" if n > 100 else "small"
+    print(label, sign(n), first(items))
+
+    print(len(items), n if n > 0 else -n)
+    total = sum(items) + (1 if n else 0)
+    print(total, [x if x > 0 else 0 for x in items])
@@ -0,0 +1,39 @@
+class Countdown:
+    def __init__(self, start):
+        self.start = start
+
+    def __iter__(self):
+        return iter(range(self.start, 0, -1))
+
+
+def iterate(s, items):
+    for c in "héllo":
+        print(c)
+
+    for x in items:
+        print(x)
+
+    for n in Countdown(3):
+        print(n)
+
+    for name, value in [("a", 1), ("b", 2)]:
+        print(name, value)
+
+    squares = (x * x for x in range(4))
+    for sq in squares:
+        print(sq)
+
+    chars = list(s)
+    print(chars, list(items), sum(items))
+
+    for i, pair in enumerate(zip(s, items)):
+        print(i, pair)
+
+    it = iter(items)
+    print(next(it), next(it, None))
+
+    names = [k for k, v in [("x", 1), ("y", 2)] if v > 1]
+    print(names)
+
+
+iterate("abc", [1, 2, 3])
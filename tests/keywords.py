@@ -0,0 +1,26 @@
+def area(width, height, unit="cm"):
+    return "%d %s" % (width * height, unit)
+
+print(area(height=2, width=3))
+print(area(4, unit="in", height=5))
+
+class Shape:
+    def resize(self, width, height):
+        self.width = width
+        self.height = height
+
+    def square(self, size):
+        self.resize(height=size, width=size)
+
+s = Shape()
+s.resize(height=10, width=20)
+
+class Store:
+    def get(self, key):
+        return key
+
+def lookup(m, k):
+    # m is not known to be a Store: this is not Store.get
+    return m.get(k, 0)
+
+print(Store().get(key="a"), lookup({"a": 1}, "b"))
@@ -0,0 +1,31 @@
+from typing import Callable, List
+
+
+def apply(f: Callable[[int], int], values: List[int]) -> List[int]:
+    return [f(v) for v in values]
+
+
+def adder(n: int) -> Callable[[int], int]:
+    return lambda x: x + n
+
+
+def lambdas(pairs, words):
+    by_second = sorted(pairs, key=lambda p: p[1])
+    lengths = list(map(lambda w: len(w), words))
+    longest = max(words, key=lambda w: len(w))
+    print(by_second, lengths, longest)
+
+    double = lambda x: x * 2
+    print(double(4), apply(lambda x: x * x, [1, 2, 3]), adder(5)(1))
+
+    scale = 10
+    scaled = lambda x, k=scale: x * k
+    scale = 0
+    print(scaled(3), (lambda a, b: a + b)(1, 2))
+    print(scaled(3, 5), (lambda a, b=2: a * b)(4, 3))
+
+    spread = lambda first, *rest: len(rest) + first
+    print(spread(1), spread(1, 2, 3))
+
+    fallback = lambda x, y=None: x if y is None else y
+    print(fallback(1), fallback(1, None), fallback(1, 2))
@@ -5,3 +5,16 @@ print([x.upper() for x in ["one", "two", "three", "four", "five", "six"]])
 print([x.upper() for x in ["one", "two", "three", "four", "five", "six"] if len(x) <= 4])
 
 print([x for x in range(10)])
+
+def squares(n: int) -> list[int]:
+    return [i * i for i in range(n)]
+
+def lengths(words: list[str]) -> list[int]:
+    return [len(w) for w in words]
+
+def firsts(pairs: list[tuple[str, int]]) -> list[str]:
+    return [k for k, v in pairs]
+
+print(squares(5), lengths(["a", "bb"]), firsts([("a", 1), ("b", 2)]))
+
+print([(x, y) for x in range(4) if x % 2 == 0 for y in range(x) if y > 0 or x > 2 if y != 1])
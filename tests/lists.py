@@ -0,0 +1,34 @@
+def stack(values: list[int], more: list[int]):
+    values.extend(more)
+    values += [4, 5]
+    values.insert(0, -1)
+    top = values.pop()
+    first = values.pop(0)
+    values.remove(3)
+    del values[1:3]
+    del values[-1]
+    values.sort(reverse=True)
+    print(top, first, values.index(2), values.count(2), values + more, more * 2)
+    values.insert(100, 7)
+    values.insert(-100, 8)
+    del values[::2]
+    del more[-1:0:-2]
+    return values.copy()
+
+def named(v: list[int], n: int, e: int, i: int):
+    # the names of the variables used by the translation of the methods
+    print(v.count(n), v.count(e), v.index(i))
+    v.remove(i)
+    return v.pop(), v.pop(0)
+
+def by_length(words: list[str]):
+    words.sort(key=len)
+    return words
+
+mixed = [3, "a", 1.5]
+mixed.append(2)
+mixed.remove("a")
+mixed.sort()
+print(mixed.pop(), mixed.index(2), stack([2, 2, 2, 3], [7]), by_length(["ccc", "a", "bb"]))
+mixed.clear()
+print(named([1, 2, 2, 3], 2, 3, 1))
@@ -0,0 +1,51 @@
+def find(items, target):
+    for i, x in enumerate(items):
+        if x == target:
+            print("found at", i)
+            break
+    else:
+        print("not found")
+
+    n = 0
+    while n < 3:
+        n += 1
+    else:
+        print("while done", n)
+
+    for x in items:
+        for y in items:
+            if y > x:
+                break
+        else:
+            print(x, "is the largest")
+            break
+    else:
+        print("no largest")
+
+    for x in items:
+        try:
+            if x == target:
+                break
+            if x < 0:
+                continue
+            print(x)
+        except ValueError:
+            break
+    else:
+        print("target not found")
+
+    for x in items:
+        try:
+            if x == target:
+                break
+            if x < 0:
+                continue
+        except ValueError:
+            print("invalid", x)
+        else:
+            # skipped by break and continue
+            print("checked", x)
+
+
+find([1, 2, 3], 2)
+find([1, 2, 3], 5)
@@ -0,0 +1,30 @@
+def lower(words, items, n):
+    upper = [w.upper() for w in words if w]
+    lengths = {w: len(w) for w in words}
+    initials = {w[0] for w in words}
+    print(upper, lengths, initials)
+
+    print([x * 2 for x in items], len([x for x in items if x > n]))
+
+    if isinstance(n, int):
+        print("int")
+    elif isinstance(n, str):
+        print("str")
+
+    for w in [w for w in words if len(w) > n]:
+        print(w)
+
+    total = 0
+    total += sum([x for x in items])
+    items = [x + 1 for x in items]
+    print(total, items)
+
+    word = [word for word in words if word]
+    x = {x: len(x) for x in word}
+    print(word, x)
+
+    while len([w for w in words if w]) > 10:
+        words = words[1:]
+
+    assert isinstance(words, list), "words should be a list"
+    return [w for w in words] if words else None
@@ -0,0 +1,28 @@
+from typing import Dict
+
+def settings(defaults: Dict[str, int], **options):
+    level = defaults.get("level", 1)
+    if defaults.get("missing") is None:
+        print("no missing")
+    debug = options.get("debug")
+    verbose = options.get("verbose", False)
+    size = defaults.setdefault("size", 10)
+    old = defaults.pop("old", 0)
+    defaults.update(extra=1)
+    options.update(defaults)
+    options.update({"one": 1, "two": 2.0})
+    last = defaults.pop("size")
+    names = defaults.keys()
+    values = defaults.values()
+    backup = defaults.copy()
+    if "level" in defaults and "x" not in options:
+        print(level, debug, verbose, size, old, last, names, values, backup)
+    for k in defaults:
+        print(k)
+    for v in options.values():
+        print(v)
+    for k, v in defaults.items():
+        print(k, v)
+    pairs = defaults.items()
+    defaults.clear()
+    return pairs
@@ -0,0 +1,40 @@
+def ranges(n, step: int):
+    evens = list(range(0, n, 2))
+    print(evens)
+
+    r = range(10, 0, -3)
+    print(len(r), r[0], r[-1], r[1:3])
+
+    if 7 in r:
+        print("7 in", r)
+
+    if n not in range(3):
+        print(n, "not in range(3)")
+
+    for i in range(n, 0, -1):
+        print(i)
+
+    for i in r:
+        print(i)
+
+    for i, x in enumerate(range(3)):
+        print(i, x)
+
+    for i, s in zip(range(n), ["a", "b", "c"]):
+        print(i, s)
+
+    squares = [x * x for x in range(n, -1, -2)]
+    print(squares)
+
+    total = sum(range(n))
+    print(total)
+
+
+    for i in range(n, 0, step):
+        print(i)
+
+    evens.extend(range(10, 13))
+    print(evens)
+
+
+ranges(5, -1)
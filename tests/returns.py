@@ -0,0 +1,48 @@
+from typing import Tuple
+
+
+def minmax(items):
+    lo = hi = items[0]
+    for x in items:
+        if x < lo:
+            lo = x
+        if x > hi:
+            hi = x
+    return lo, hi
+
+
+def split(name: str) -> Tuple[str, str]:
+    if " " in name:
+        return tuple(name.split(" ", 1))
+    return name, ""
+
+
+def swap(a: int, b: int):
+    return b, a
+
+
+def bounds(items):
+    return minmax(items)
+
+
+def maybe(x):
+    # falls off the end (returning None) when x is false: a single result
+    if x:
+        return 1, 2
+
+
+def sign(x):
+    if x < 0:
+        return -1, "negative"
+    else:
+        return 1, "positive"
+
+
+def returns():
+    lo, hi = minmax([3, 1, 2])
+    first, last = split("Ada Lovelace")
+    x, y = swap(1, 2)
+    pair = swap(x, y)
+    print(lo, hi, first, last, x, y, pair, bounds([5, 4]))
+    print(len(swap(3, 4)))
+    print(maybe(0), maybe(1), sign(-2))
@@ -0,0 +1,25 @@
+def uniq(words: list[str]):
+    seen = set()
+    for w in words:
+        seen.add(w)
+    return seen
+
+def compare(a: list[int], b: list[int]):
+    sa = set(a)
+    sb = {x for x in b if x > 0}
+    both = sa & sb
+    either = sa | sb
+    only = sa - sb
+    one = sa ^ sb
+    sa |= {100}
+    sa.difference_update(b)
+    if 3 in sa and sb <= either:
+        print(both, either, only, one, sa.union(b), frozenset(b))
+    for x in sb:
+        print(x)
+    return len(both) if both else 0
+
+small = {1, 2, 3}
+names = {"a", "b"}
+mixed = {1, "x"}
+print(uniq(["a", "b", "a"]), 2 in small, mixed)
@@ -0,0 +1,27 @@
+class Stack:
+    def __len__(self):
+        return len(self.items)
+
+def check(items, name, n):
+    if items:
+        print("not empty")
+    if not name:
+        print("no name")
+    while n:
+        n -= 1
+    s = Stack()
+    if s and items:
+        print("stack")
+    label = name or "default"
+    count = n or 10
+    return label if items else None
+
+x = 0
+if x or (x == 1 and not x):
+    print(x)
+
+def notify(msg):
+    print(msg)
+
+if not notify("sent"):
+    print("notify returns None")
@@ -0,0 +1,23 @@
+def total(*values):
+    n = 0
+    for v in values:
+        n += v
+    return n
+
+def tag(name, *children, **attrs):
+    print(name, children, attrs)
+
+nums = [1, 2, 3]
+print(total(1, 2, 3))
+print(total(*nums))
+print(total(0, *nums))
+tag("div", "a", "b", id="main")
+opts = {"class": "x"}
+tag("span", **opts)
+tag("p", title="t", **opts)
+
+def sizes(**widths: int):
+    return sum(widths.values())
+
+more = {"c": 3, "d": 4}
+print(sizes(a=1, b=2), sizes(e=5, **{"f": 6}), sizes(**more))